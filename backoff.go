@@ -0,0 +1,156 @@
+package roku
+
+import (
+	"fmt"
+	"github.com/cenkalti/backoff/v4"
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	MaxRetryInterval = time.Minute
+	RetryMultiplier  = 1.5
+	RetryJitter      = 0.5
+)
+
+var (
+	ErrRetryPolicy = rokuErr("invalid retry policy")
+)
+
+type (
+	// BackoffStrategy returns the delay before the given retry, starting at 1.
+	// previous is the delay waited before the previous retry, zero before the
+	// first one. The delay should stay within the MaxInterval of policy.
+	BackoffStrategy func(policy RetryPolicy, retry uint64, previous time.Duration) time.Duration
+
+	// RetryPolicy configures how FetchRx and SSERx wait between attempts.
+	RetryPolicy struct {
+		// Strategy computes the delays. It defaults to ExponentialBackoff.
+		Strategy BackoffStrategy
+		// InitialInterval is the base delay. It defaults to RetryInterval.
+		InitialInterval time.Duration
+		// MaxInterval caps every delay. It defaults to MaxRetryInterval.
+		MaxInterval time.Duration
+		// MaxElapsedTime stops retrying once that much time has passed since
+		// the first attempt. Zero means no limit.
+		MaxElapsedTime time.Duration
+		// Multiplier grows the delays of ExponentialBackoff. It defaults to
+		// RetryMultiplier.
+		Multiplier float64
+		// Jitter randomizes each delay by up to that fraction of it, between 0
+		// and 1. Zero disables it.
+		Jitter float64
+		// MaxRetries is the number of retries after the first attempt.
+		MaxRetries uint64
+	}
+
+	policyBackOff struct {
+		policy   RetryPolicy
+		retry    uint64
+		previous time.Duration
+		start    time.Time
+	}
+)
+
+// NewRetryPolicy fills the zero fields of policy with their defaults and
+// checks the others, returning an ErrRetryPolicy error when one is invalid.
+func NewRetryPolicy(policy RetryPolicy) (RetryPolicy, error) {
+	if policy.Strategy == nil {
+		policy.Strategy = ExponentialBackoff
+	}
+
+	if policy.InitialInterval == 0 {
+		policy.InitialInterval = RetryInterval
+	}
+
+	if policy.MaxInterval == 0 {
+		policy.MaxInterval = max(MaxRetryInterval, policy.InitialInterval)
+	}
+
+	if policy.Multiplier == 0 {
+		policy.Multiplier = RetryMultiplier
+	}
+
+	switch {
+	case policy.InitialInterval < 0:
+		return RetryPolicy{}, fmt.Errorf("%w: negative initial interval %v", ErrRetryPolicy, policy.InitialInterval)
+	case policy.MaxInterval < policy.InitialInterval:
+		return RetryPolicy{}, fmt.Errorf("%w: max interval %v is lower than the initial interval %v", ErrRetryPolicy, policy.MaxInterval, policy.InitialInterval)
+	case policy.MaxElapsedTime < 0:
+		return RetryPolicy{}, fmt.Errorf("%w: negative max elapsed time %v", ErrRetryPolicy, policy.MaxElapsedTime)
+	case policy.Multiplier < 1:
+		return RetryPolicy{}, fmt.Errorf("%w: multiplier %v is lower than 1", ErrRetryPolicy, policy.Multiplier)
+	case policy.Jitter < 0 || policy.Jitter > 1:
+		return RetryPolicy{}, fmt.Errorf("%w: jitter %v is not between 0 and 1", ErrRetryPolicy, policy.Jitter)
+	}
+
+	return policy, nil
+}
+
+// ConstantBackoff waits InitialInterval before every retry, with jitter.
+func ConstantBackoff(policy RetryPolicy, _ uint64, _ time.Duration) time.Duration {
+	return withJitter(policy, float64(policy.InitialInterval))
+}
+
+// LinearBackoff waits InitialInterval more before every retry, with jitter.
+func LinearBackoff(policy RetryPolicy, retry uint64, _ time.Duration) time.Duration {
+	return withJitter(policy, float64(policy.InitialInterval)*float64(retry))
+}
+
+// ExponentialBackoff multiplies the delay by Multiplier before every retry,
+// with jitter.
+func ExponentialBackoff(policy RetryPolicy, retry uint64, _ time.Duration) time.Duration {
+	return withJitter(policy, float64(policy.InitialInterval)*math.Pow(policy.Multiplier, float64(retry-1)))
+}
+
+// DecorrelatedJitterBackoff picks a random delay between InitialInterval and
+// three times the previous one. It is random by itself, so Jitter is not
+// applied on top of it.
+func DecorrelatedJitterBackoff(policy RetryPolicy, _ uint64, previous time.Duration) time.Duration {
+	upper := 3 * float64(max(previous, policy.InitialInterval))
+	lower := float64(policy.InitialInterval)
+	return capDelay(lower+rand.Float64()*(upper-lower), policy.MaxInterval)
+}
+
+// withJitter randomizes delay by up to the Jitter fraction of it, and caps
+// the result at MaxInterval.
+func withJitter(policy RetryPolicy, delay float64) time.Duration {
+	delay = min(delay, float64(policy.MaxInterval))
+	spread := policy.Jitter * delay
+	return capDelay(delay-spread+rand.Float64()*2*spread, policy.MaxInterval)
+}
+
+func capDelay(delay float64, maxInterval time.Duration) time.Duration {
+	if delay >= float64(maxInterval) {
+		return maxInterval
+	}
+	return time.Duration(delay)
+}
+
+// backOff returns the delays of a new call, counted from now.
+func (p RetryPolicy) backOff() backoff.BackOff {
+	return &policyBackOff{policy: p, start: time.Now()}
+}
+
+func (b *policyBackOff) Reset() {
+	b.retry = 0
+	b.previous = 0
+	b.start = time.Now()
+}
+
+func (b *policyBackOff) NextBackOff() time.Duration {
+	if b.retry >= b.policy.MaxRetries {
+		return backoff.Stop
+	}
+	b.retry++
+
+	delay := b.policy.Strategy(b.policy, b.retry, b.previous)
+
+	if b.policy.MaxElapsedTime > 0 && time.Since(b.start)+delay > b.policy.MaxElapsedTime {
+		return backoff.Stop
+	}
+
+	b.previous = delay
+	return delay
+}
@@ -0,0 +1,244 @@
+package roku
+
+import (
+	"context"
+	"errors"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/google/go-cmp/cmp"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBuildingRetryPolicyValidatesIt(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input   RetryPolicy
+		wantErr bool
+	}{
+		"with zero value":                  {input: RetryPolicy{}},
+		"with every field":                 {input: RetryPolicy{Strategy: LinearBackoff, InitialInterval: time.Second, MaxInterval: time.Minute, MaxElapsedTime: time.Hour, Multiplier: 2, Jitter: 1, MaxRetries: 5}},
+		"with negative initial interval":   {input: RetryPolicy{InitialInterval: -time.Second}, wantErr: true},
+		"with max lower than initial":      {input: RetryPolicy{InitialInterval: time.Second, MaxInterval: time.Millisecond}, wantErr: true},
+		"with negative max elapsed time":   {input: RetryPolicy{MaxElapsedTime: -time.Second}, wantErr: true},
+		"with multiplier lower than one":   {input: RetryPolicy{Multiplier: 0.5}, wantErr: true},
+		"with negative jitter":             {input: RetryPolicy{Jitter: -0.1}, wantErr: true},
+		"with jitter greater than one":     {input: RetryPolicy{Jitter: 1.5}, wantErr: true},
+		"with initial beyond default max":  {input: RetryPolicy{InitialInterval: 2 * MaxRetryInterval}},
+		"with defaults for omitted fields": {input: RetryPolicy{MaxRetries: 1}},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			got, err := NewRetryPolicy(tc.input)
+			if tc.wantErr {
+				if !errors.Is(err, ErrRetryPolicy) {
+					t.Errorf("Expected: %v, Got: %v", ErrRetryPolicy, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got.Strategy == nil || got.InitialInterval <= 0 || got.MaxInterval < got.InitialInterval || got.Multiplier < 1 {
+				t.Errorf("Expected defaults to be filled, Got: %+v", got)
+			}
+		})
+	}
+}
+
+func TestComputingBackoffDelaysFollowsStrategy(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input RetryPolicy
+		want  []time.Duration
+	}{
+		"with constant": {
+			input: RetryPolicy{Strategy: ConstantBackoff, InitialInterval: 10 * time.Millisecond, MaxRetries: 3},
+			want:  []time.Duration{10 * time.Millisecond, 10 * time.Millisecond, 10 * time.Millisecond, backoff.Stop},
+		},
+		"with linear": {
+			input: RetryPolicy{Strategy: LinearBackoff, InitialInterval: 10 * time.Millisecond, MaxInterval: 25 * time.Millisecond, MaxRetries: 3},
+			want:  []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 25 * time.Millisecond, backoff.Stop},
+		},
+		"with exponential": {
+			input: RetryPolicy{Strategy: ExponentialBackoff, InitialInterval: 10 * time.Millisecond, Multiplier: 2, MaxRetries: 4},
+			want:  []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 80 * time.Millisecond, backoff.Stop},
+		},
+		"with max elapsed time": {
+			input: RetryPolicy{Strategy: LinearBackoff, InitialInterval: time.Second, MaxElapsedTime: 1500 * time.Millisecond, MaxRetries: 3},
+			want:  []time.Duration{time.Second, backoff.Stop},
+		},
+		"with no retries": {
+			input: RetryPolicy{MaxRetries: 0},
+			want:  []time.Duration{backoff.Stop},
+		},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			policy, err := NewRetryPolicy(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b := policy.backOff()
+
+			var got []time.Duration
+			for range tc.want {
+				got = append(got, b.NextBackOff())
+			}
+
+			if !cmp.Equal(tc.want, got) {
+				t.Error(cmp.Diff(tc.want, got))
+			}
+		})
+	}
+}
+
+func TestComputingBackoffDelaysWithJitterStaysInRange(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input    RetryPolicy
+		min, max time.Duration
+	}{
+		"with constant and jitter": {
+			input: RetryPolicy{Strategy: ConstantBackoff, InitialInterval: 100 * time.Millisecond, Jitter: 0.5, MaxRetries: 50},
+			min:   50 * time.Millisecond,
+			max:   150 * time.Millisecond,
+		},
+		"with decorrelated jitter": {
+			input: RetryPolicy{Strategy: DecorrelatedJitterBackoff, InitialInterval: 100 * time.Millisecond, MaxInterval: time.Second, MaxRetries: 50},
+			min:   100 * time.Millisecond,
+			max:   time.Second,
+		},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			policy, err := NewRetryPolicy(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b := policy.backOff()
+			for delay := b.NextBackOff(); delay != backoff.Stop; delay = b.NextBackOff() {
+				if delay < tc.min || delay > tc.max {
+					t.Fatalf("Expected a delay between %v and %v, Got: %v", tc.min, tc.max, delay)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchingWithRetryPolicyUsesIt(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		errorResponse(w, r, http.StatusBadGateway, http.StatusText(http.StatusBadGateway))
+	})
+	defer ts.Close()
+
+	policy, err := NewRetryPolicy(RetryPolicy{Strategy: ConstantBackoff, InitialInterval: time.Millisecond, MaxRetries: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClient(WithHTTPClient(httpClient), WithRetryPolicy(policy))
+
+	ch := SendRx[NoReq, getUserEnvV1Res](context.Background(), client, Get, ts.URL, nil).Observe()
+
+	_, err = To[Envelope[getUserEnvV1Res]](<-ch)
+
+	var errHTTP ErrInvalidHTTPStatus
+	if !errors.As(err, &errHTTP) {
+		t.Fatalf("wrong error: %v", err)
+	}
+
+	if calls.Load() != 3 {
+		t.Errorf("Expected 3 attempts, Got: %d", calls.Load())
+	}
+}
+
+func TestFetchingWithUnbuiltRetryPolicyFillsDefaults(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		errorResponse(w, r, http.StatusServiceUnavailable, http.StatusText(http.StatusServiceUnavailable))
+	})
+	defer ts.Close()
+
+	client := NewClient(
+		WithHTTPClient(httpClient),
+		WithRetryPolicy(RetryPolicy{InitialInterval: time.Millisecond, MaxRetries: 2}),
+	)
+
+	ch := SendRx[NoReq, getUserEnvV1Res](context.Background(), client, Get, ts.URL, nil).Observe()
+
+	_, err := To[Envelope[getUserEnvV1Res]](<-ch)
+
+	var errHTTP ErrInvalidHTTPStatus
+	if !errors.As(err, &errHTTP) {
+		t.Fatalf("wrong error: %v", err)
+	}
+
+	if calls.Load() != 3 {
+		t.Errorf("Expected 3 attempts, Got: %d", calls.Load())
+	}
+}
+
+func TestFetchingWithInvalidRetryPolicyFailsBeforeSending(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		getUserHandler(w, r)
+	})
+	defer ts.Close()
+
+	client := NewClient(
+		WithHTTPClient(httpClient),
+		WithBaseURL(ts.URL),
+		WithRetryPolicy(RetryPolicy{InitialInterval: time.Second, MaxInterval: time.Millisecond, MaxRetries: 9}),
+	)
+
+	cases := map[string]func() error{
+		"with SendRx": func() error {
+			_, err := To[Envelope[getUserEnvV1Res]](<-SendRx[NoReq, getUserEnvV1Res](context.Background(), client, Get, "/users", nil).Observe())
+			return err
+		},
+		"with SendSSERx": func() error {
+			_, err := To[Event[string]](<-SendSSERx[NoReq, string](context.Background(), client, Get, "/events", nil).Observe())
+			return err
+		},
+	}
+
+	for input, send := range cases {
+		t.Run(input, func(t *testing.T) {
+			if err := send(); !errors.Is(err, ErrRetryPolicy) {
+				t.Errorf("Expected: %v, Got: %v", ErrRetryPolicy, err)
+			}
+		})
+	}
+
+	if calls.Load() != 0 {
+		t.Errorf("Expected no request, Got: %d", calls.Load())
+	}
+
+	valid := WithRetryPolicy(RetryPolicy{MaxRetries: 1})
+	ch := SendRx[NoReq, getUserEnvV1Res](context.Background(), client, Get, "/users", nil, valid).Observe()
+	if _, err := To[Envelope[getUserEnvV1Res]](<-ch); err != nil {
+		t.Errorf("Expected a valid policy of the call to replace it, Got: %v", err)
+	}
+}
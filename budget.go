@@ -0,0 +1,116 @@
+package roku
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	budgetSlots = 10
+)
+
+var (
+	ErrRetryBudget = rokuErr("invalid retry budget")
+)
+
+type (
+	// RetryBudget limits the retries sent to each host to a ratio of the calls
+	// made to it over a sliding window, so that retries cannot multiply the
+	// load of a struggling host.
+	RetryBudget struct {
+		ratio      float64
+		minRetries int
+		slot       time.Duration
+		mu         sync.Mutex
+		hosts      map[string]*[budgetSlots]budgetSlot
+	}
+
+	// RetryBudgetHook is called with the host and the error of a failed
+	// attempt that was not retried because the budget was exhausted.
+	RetryBudgetHook func(host string, err error)
+
+	budgetSlot struct {
+		id      int64
+		calls   int
+		retries int
+	}
+)
+
+// NewRetryBudget allows, for each host, minRetries retries plus ratio times
+// the calls made to it within window.
+func NewRetryBudget(ratio float64, minRetries int, window time.Duration) (*RetryBudget, error) {
+	switch {
+	case ratio < 0:
+		return nil, fmt.Errorf("%w: negative retry ratio %v", ErrRetryBudget, ratio)
+	case minRetries < 0:
+		return nil, fmt.Errorf("%w: negative min retries %d", ErrRetryBudget, minRetries)
+	case window < budgetSlots:
+		return nil, fmt.Errorf("%w: window %v is too short", ErrRetryBudget, window)
+	}
+
+	budget := RetryBudget{
+		ratio:      ratio,
+		minRetries: minRetries,
+		slot:       window / budgetSlots,
+		hosts:      make(map[string]*[budgetSlots]budgetSlot),
+	}
+	return &budget, nil
+}
+
+// deposit records a call to host.
+func (b *RetryBudget) deposit(host string, now time.Time) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.current(host, now).calls++
+}
+
+// withdraw records a retry to host and reports whether the budget allowed it.
+func (b *RetryBudget) withdraw(host string, now time.Time) bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	current := b.current(host, now)
+
+	var calls, retries int
+	for _, slot := range b.hosts[host] {
+		if slot.id > current.id-budgetSlots {
+			calls += slot.calls
+			retries += slot.retries
+		}
+	}
+
+	if float64(retries) >= float64(b.minRetries)+b.ratio*float64(calls) {
+		return false
+	}
+
+	current.retries++
+	return true
+}
+
+// current returns the slot of host holding now, emptied when it was last
+// used by an earlier window.
+func (b *RetryBudget) current(host string, now time.Time) *budgetSlot {
+	slots, ok := b.hosts[host]
+	if !ok {
+		slots = new([budgetSlots]budgetSlot)
+		b.hosts[host] = slots
+	}
+
+	id := now.UnixNano() / int64(b.slot)
+	slot := &slots[id%budgetSlots]
+	if slot.id != id {
+		*slot = budgetSlot{id: id}
+	}
+
+	return slot
+}
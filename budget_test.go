@@ -0,0 +1,120 @@
+package roku
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithdrawingFromRetryBudgetFollowsRatio(t *testing.T) {
+	t.Parallel()
+
+	budget, err := NewRetryBudget(0.1, 1, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	for i := 0; i < 20; i++ {
+		budget.deposit("a.example", now)
+	}
+
+	var allowed int
+	for i := 0; i < 10; i++ {
+		if budget.withdraw("a.example", now) {
+			allowed++
+		}
+	}
+
+	if allowed != 3 {
+		t.Errorf("Expected 3 retries, Got: %d", allowed)
+	}
+
+	if !budget.withdraw("b.example", now) {
+		t.Error("Expected the budget of another host to be untouched")
+	}
+
+	if !budget.withdraw("a.example", now.Add(11*time.Second)) {
+		t.Error("Expected the budget to be refilled once the window slid")
+	}
+}
+
+func TestBuildingRetryBudgetValidatesIt(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		ratio      float64
+		minRetries int
+		window     time.Duration
+	}{
+		"with negative ratio":       {ratio: -0.1, minRetries: 1, window: time.Second},
+		"with negative min retries": {ratio: 0.1, minRetries: -1, window: time.Second},
+		"with zero window":          {ratio: 0.1, minRetries: 1, window: 0},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			_, err := NewRetryBudget(tc.ratio, tc.minRetries, tc.window)
+			if !errors.Is(err, ErrRetryBudget) {
+				t.Errorf("Expected: %v, Got: %v", ErrRetryBudget, err)
+			}
+		})
+	}
+}
+
+func TestFetchingWithExhaustedRetryBudgetReturnsOriginalError(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		errorResponse(w, r, http.StatusServiceUnavailable, http.StatusText(http.StatusServiceUnavailable))
+	})
+	defer ts.Close()
+
+	budget, err := NewRetryBudget(0, 1, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var hosts []string
+	hook := func(host string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		hosts = append(hosts, host)
+	}
+
+	client := NewClient(
+		WithHTTPClient(httpClient),
+		WithBaseURL(ts.URL),
+		WithRetries(time.Millisecond, 3),
+		WithRetryBudget(budget),
+		WithRetryBudgetHook(hook),
+	)
+
+	for i := 0; i < 2; i++ {
+		ch := SendRx[NoReq, getUserEnvV1Res](context.Background(), client, Get, "/users", nil).Observe()
+
+		_, err := To[Envelope[getUserEnvV1Res]](<-ch)
+
+		var errHTTP ErrInvalidHTTPStatus
+		if !errors.As(err, &errHTTP) || errHTTP.Res.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("wrong error: %v", err)
+		}
+	}
+
+	if calls.Load() != 3 {
+		t.Errorf("Expected 3 attempts, Got: %d", calls.Load())
+	}
+
+	u, _ := url.Parse(ts.URL)
+	if len(hosts) != 2 || hosts[0] != u.Host {
+		t.Errorf("Expected the hook to be called twice with %q, Got: %v", u.Host, hosts)
+	}
+}
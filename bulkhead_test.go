@@ -0,0 +1,61 @@
+package roku
+
+import (
+	"context"
+	"errors"
+	"github.com/v8tix/roku/middleware"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestFetchingWithFullBulkheadRejectsCalls(t *testing.T) {
+	t.Parallel()
+
+	arrived := make(chan struct{})
+	unblock := make(chan struct{})
+	ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-unblock
+		getUserHandler(w, r)
+	})
+	defer ts.Close()
+
+	bulkhead, err := middleware.NewBulkhead(1, 0, 0, middleware.ByHost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClient(
+		WithHTTPClient(httpClient),
+		WithBaseURL(ts.URL),
+		WithRetries(100*time.Millisecond, 3),
+		WithMiddleware(bulkhead.Wrap),
+	)
+
+	send := func() <-chan error {
+		errs := make(chan error, 1)
+		go func() {
+			ch := SendRx[NoReq, getUserEnvV1Res](context.Background(), client, Get, "/users", nil).Observe()
+			_, err := To[Envelope[getUserEnvV1Res]](<-ch)
+			errs <- err
+		}()
+		return errs
+	}
+
+	inFlight := send()
+	<-arrived
+
+	start := time.Now()
+	if err := <-send(); !errors.Is(err, middleware.ErrBulkheadFull) {
+		t.Errorf("Expected: %v, Got: %v", middleware.ErrBulkheadFull, err)
+	}
+	if elapsed := time.Since(start); elapsed >= 100*time.Millisecond {
+		t.Errorf("Expected the call to be rejected without retries, Got: %v", elapsed)
+	}
+
+	close(unblock)
+	if err := <-inFlight; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,304 @@
+package roku
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+const (
+	ByHost CircuitKey = iota
+	ByEndpoint
+)
+
+const (
+	circuitSuccess circuitResult = iota
+	circuitFailure
+	circuitIgnored
+)
+
+var (
+	ErrCircuitOpen    = rokuErr("circuit breaker is open")
+	ErrCircuitBreaker = rokuErr("invalid circuit breaker settings")
+)
+
+type (
+	CircuitState int
+
+	// CircuitKey selects what a circuit breaker keeps a circuit for: every
+	// host, or every method and endpoint template, e.g. "GET /users/{id}".
+	CircuitKey int
+
+	// CircuitStateHook is called when the circuit of key goes from one state to
+	// another.
+	CircuitStateHook func(key string, from, to CircuitState)
+
+	stateChange struct {
+		key      string
+		from, to CircuitState
+	}
+
+	// CircuitBreakerSettings configures NewCircuitBreaker. Zero fields take
+	// their defaults.
+	CircuitBreakerSettings struct {
+		// Key selects what a circuit is kept for. It defaults to ByHost.
+		Key CircuitKey
+		// FailureRate opens a closed circuit once that fraction of its calls
+		// failed, between 0 and 1. It defaults to 0.5.
+		FailureRate float64
+		// MinRequests is the number of calls a closed circuit needs before its
+		// failure rate is considered. It defaults to 20.
+		MinRequests int
+		// Interval clears the counts of closed circuits periodically. Zero
+		// keeps them until the circuit opens.
+		Interval time.Duration
+		// OpenDuration is how long an open circuit rejects calls before letting
+		// probes through. It defaults to 30 seconds.
+		OpenDuration time.Duration
+		// HalfOpenProbes is the number of successful probes that close a
+		// half-open circuit. It defaults to 1.
+		HalfOpenProbes int
+		// OnStateChange is called on every state change.
+		OnStateChange CircuitStateHook
+	}
+
+	// CircuitBreaker rejects calls with ErrCircuitOpen, without sending them,
+	// while the service they target keeps failing. Calls fail when the status
+	// code validator rejects their response or when they time out or cannot
+	// reach the service.
+	CircuitBreaker struct {
+		settings CircuitBreakerSettings
+		mu       sync.Mutex
+		circuits map[string]*circuit
+		changes  []stateChange
+	}
+
+	circuit struct {
+		state      CircuitState
+		generation uint64
+		since      time.Time
+		calls      int
+		failures   int
+		inFlight   int
+	}
+
+	circuitResult int
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// NewCircuitBreaker returns a breaker whose circuits all start closed.
+func NewCircuitBreaker(settings CircuitBreakerSettings) (*CircuitBreaker, error) {
+	if settings.FailureRate == 0 {
+		settings.FailureRate = 0.5
+	}
+
+	if settings.MinRequests == 0 {
+		settings.MinRequests = 20
+	}
+
+	if settings.OpenDuration == 0 {
+		settings.OpenDuration = 30 * time.Second
+	}
+
+	if settings.HalfOpenProbes == 0 {
+		settings.HalfOpenProbes = 1
+	}
+
+	switch {
+	case settings.Key != ByHost && settings.Key != ByEndpoint:
+		return nil, fmt.Errorf("%w: unknown key %d", ErrCircuitBreaker, settings.Key)
+	case settings.FailureRate < 0 || settings.FailureRate > 1:
+		return nil, fmt.Errorf("%w: failure rate %v is not between 0 and 1", ErrCircuitBreaker, settings.FailureRate)
+	case settings.MinRequests < 0:
+		return nil, fmt.Errorf("%w: negative min requests %d", ErrCircuitBreaker, settings.MinRequests)
+	case settings.Interval < 0:
+		return nil, fmt.Errorf("%w: negative interval %v", ErrCircuitBreaker, settings.Interval)
+	case settings.OpenDuration < 0:
+		return nil, fmt.Errorf("%w: negative open duration %v", ErrCircuitBreaker, settings.OpenDuration)
+	case settings.HalfOpenProbes < 0:
+		return nil, fmt.Errorf("%w: negative half-open probes %d", ErrCircuitBreaker, settings.HalfOpenProbes)
+	}
+
+	breaker := CircuitBreaker{
+		settings: settings,
+		circuits: make(map[string]*circuit),
+	}
+	return &breaker, nil
+}
+
+// State returns the current state of the circuit of key.
+func (b *CircuitBreaker) State(key string) CircuitState {
+	b.mu.Lock()
+	defer b.notify()
+
+	c, ok := b.circuits[key]
+	if !ok {
+		return CircuitClosed
+	}
+
+	return b.refresh(key, c, time.Now()).state
+}
+
+// key returns the circuit key of a call to the resolved endpoint template.
+func (b *CircuitBreaker) key(method HTTPMethod, endpoint string) string {
+	endpoint, _, _ = strings.Cut(endpoint, "?")
+
+	if b.settings.Key == ByEndpoint {
+		return string(method) + " " + endpoint
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return endpoint
+	}
+	return u.Host
+}
+
+// allow reports whether a call to key may be sent. When it may, the returned
+// function must be called with the result of the call.
+func (b *CircuitBreaker) allow(key string, now time.Time) (func(circuitResult), error) {
+	b.mu.Lock()
+	defer b.notify()
+
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{since: now}
+		b.circuits[key] = c
+	}
+	b.refresh(key, c, now)
+
+	switch {
+	case c.state == CircuitOpen,
+		c.state == CircuitHalfOpen && c.inFlight >= b.settings.HalfOpenProbes:
+		return nil, fmt.Errorf("%w for %q", ErrCircuitOpen, key)
+	}
+
+	c.inFlight++
+	generation := c.generation
+
+	return func(result circuitResult) {
+		b.record(key, generation, result, time.Now())
+	}, nil
+}
+
+func (b *CircuitBreaker) record(key string, generation uint64, result circuitResult, now time.Time) {
+	b.mu.Lock()
+	defer b.notify()
+
+	c := b.circuits[key]
+	if c.generation != generation {
+		return
+	}
+	c.inFlight--
+
+	switch {
+	case result == circuitIgnored:
+	case c.state == CircuitHalfOpen && result == circuitFailure:
+		b.transition(key, c, CircuitOpen, now)
+	case c.state == CircuitHalfOpen:
+		c.calls++
+		if c.calls >= b.settings.HalfOpenProbes {
+			b.transition(key, c, CircuitClosed, now)
+		}
+	default:
+		c.calls++
+		if result == circuitFailure {
+			c.failures++
+		}
+
+		if c.calls >= b.settings.MinRequests && float64(c.failures) >= b.settings.FailureRate*float64(c.calls) {
+			b.transition(key, c, CircuitOpen, now)
+		}
+	}
+}
+
+// refresh moves c to the state it is in at now: an open circuit becomes
+// half-open after OpenDuration, and the counts of a closed one are cleared
+// every Interval.
+func (b *CircuitBreaker) refresh(key string, c *circuit, now time.Time) *circuit {
+	switch {
+	case c.state == CircuitOpen && now.Sub(c.since) >= b.settings.OpenDuration:
+		b.transition(key, c, CircuitHalfOpen, now)
+	case c.state == CircuitClosed && b.settings.Interval > 0 && now.Sub(c.since) >= b.settings.Interval:
+		*c = circuit{generation: c.generation + 1, since: now}
+	}
+	return c
+}
+
+func (b *CircuitBreaker) transition(key string, c *circuit, to CircuitState, now time.Time) {
+	from := c.state
+	*c = circuit{state: to, generation: c.generation + 1, since: now}
+
+	if b.settings.OnStateChange != nil {
+		b.changes = append(b.changes, stateChange{key: key, from: from, to: to})
+	}
+}
+
+// notify unlocks b and then calls the hook with the state changes made while
+// it was locked, so that the hook may use b.
+func (b *CircuitBreaker) notify() {
+	changes := b.changes
+	b.changes = nil
+	b.mu.Unlock()
+
+	for _, change := range changes {
+		b.settings.OnStateChange(change.key, change.from, change.to)
+	}
+}
+
+// circuit asks the circuit breaker, if any, whether a call to the endpoint
+// template may be sent. The returned function records the outcome of the
+// call; calls canceled by their caller, or rejected before being sent with an
+// error whose Retryable method returns false, e.g. middleware.ErrRateLimited,
+// are not counted.
+func (s settings) circuit(method HTTPMethod, template string) (func(ctx context.Context, err error), error) {
+	if s.circuitBreaker == nil {
+		return func(context.Context, error) {}, nil
+	}
+
+	resolved, err := s.resolve(template)
+	if err != nil {
+		resolved = template
+	}
+
+	done, err := s.circuitBreaker.allow(s.circuitBreaker.key(method, resolved), time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, err error) {
+		var retryable interface{ Retryable() bool }
+		switch {
+		case ctx.Err() != nil:
+			done(circuitIgnored)
+		case errors.As(err, &retryable) && !retryable.Retryable():
+			done(circuitIgnored)
+		case err != nil:
+			done(circuitFailure)
+		default:
+			done(circuitSuccess)
+		}
+	}, nil
+}
@@ -0,0 +1,210 @@
+package roku
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"github.com/v8tix/roku/middleware"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTrippingCircuitBreakerGoesThroughStates(t *testing.T) {
+	t.Parallel()
+
+	var changes []string
+	breaker, err := NewCircuitBreaker(CircuitBreakerSettings{
+		FailureRate:    0.5,
+		MinRequests:    4,
+		OpenDuration:   time.Minute,
+		HalfOpenProbes: 1,
+		OnStateChange: func(key string, from, to CircuitState) {
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", key, from, to))
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	for _, result := range []circuitResult{circuitSuccess, circuitFailure, circuitIgnored, circuitSuccess, circuitFailure} {
+		done, err := breaker.allow("api", now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		done(result)
+	}
+
+	if _, err := breaker.allow("api", now); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected: %v, Got: %v", ErrCircuitOpen, err)
+	}
+
+	if _, err := breaker.allow("other", now); err != nil {
+		t.Fatalf("Expected the circuit of another key to be closed, Got: %v", err)
+	}
+
+	probe, err := breaker.allow("api", now.Add(2*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := breaker.allow("api", now.Add(2*time.Minute)); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected a single probe, Got: %v", err)
+	}
+
+	probe(circuitSuccess)
+
+	if got := breaker.State("api"); got != CircuitClosed {
+		t.Errorf("Expected: %v, Got: %v", CircuitClosed, got)
+	}
+
+	want := []string{"api: closed -> open", "api: open -> half-open", "api: half-open -> closed"}
+	if !cmp.Equal(want, changes) {
+		t.Error(cmp.Diff(want, changes))
+	}
+}
+
+func TestBuildingCircuitBreakerValidatesIt(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]CircuitBreakerSettings{
+		"with unknown key":           {Key: CircuitKey(7)},
+		"with failure rate over one": {FailureRate: 1.5},
+		"with negative min requests": {MinRequests: -1},
+		"with negative interval":     {Interval: -time.Second},
+		"with negative open":         {OpenDuration: -time.Second},
+		"with negative probes":       {HalfOpenProbes: -1},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			_, err := NewCircuitBreaker(tc)
+			if !errors.Is(err, ErrCircuitBreaker) {
+				t.Errorf("Expected: %v, Got: %v", ErrCircuitBreaker, err)
+			}
+		})
+	}
+}
+
+func TestFetchingWithOpenCircuitDoesNotSendRequest(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		key      CircuitKey
+		wantKey  func(baseURL string) string
+		endpoint string
+	}{
+		"with host key": {
+			key:      ByHost,
+			wantKey:  func(baseURL string) string { return baseURL[len("http://"):] },
+			endpoint: "/users/{id}",
+		},
+		"with endpoint key": {
+			key:      ByEndpoint,
+			wantKey:  func(baseURL string) string { return "GET " + baseURL + "/users/{id}" },
+			endpoint: "/users/{id}",
+		},
+	}
+
+	for input, tc := range cases {
+		tc := tc
+		t.Run(input, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+			ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				errorResponse(w, r, http.StatusServiceUnavailable, http.StatusText(http.StatusServiceUnavailable))
+			})
+			defer ts.Close()
+
+			breaker, err := NewCircuitBreaker(CircuitBreakerSettings{Key: tc.key, MinRequests: 2})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := NewClient(
+				WithHTTPClient(httpClient),
+				WithBaseURL(ts.URL),
+				WithRetries(time.Millisecond, 0),
+				WithCircuitBreaker(breaker),
+			)
+
+			for i, id := range []string{"1", "2", "3"} {
+				_, err := Send[NoReq, getUserEnvV1Res](
+					context.Background(),
+					client,
+					Get,
+					tc.endpoint,
+					nil,
+					WithPathParams(map[string]string{"id": id}),
+				)
+
+				var errHTTP ErrInvalidHTTPStatus
+				if i < 2 && !errors.As(err, &errHTTP) {
+					t.Fatalf("wrong error: %v", err)
+				}
+				if i == 2 && !errors.Is(err, ErrCircuitOpen) {
+					t.Fatalf("Expected: %v, Got: %v", ErrCircuitOpen, err)
+				}
+			}
+
+			if calls.Load() != 2 {
+				t.Errorf("Expected 2 requests, Got: %d", calls.Load())
+			}
+
+			if got := breaker.State(tc.wantKey(ts.URL)); got != CircuitOpen {
+				t.Errorf("Expected: %v, Got: %v", CircuitOpen, got)
+			}
+		})
+	}
+}
+
+func TestFetchingWithRateLimiterDoesNotTripCircuit(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		getUserHandler(w, r)
+	})
+	defer ts.Close()
+
+	limiter, err := middleware.NewRateLimiter(0.001, 1, middleware.ByHost, middleware.FailFast)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	breaker, err := NewCircuitBreaker(CircuitBreakerSettings{MinRequests: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClient(
+		WithHTTPClient(httpClient),
+		WithBaseURL(ts.URL),
+		WithMiddleware(limiter.Wrap),
+		WithCircuitBreaker(breaker),
+	)
+
+	for i := 0; i < 6; i++ {
+		_, err := Send[NoReq, getUserEnvV1Res](context.Background(), client, Get, "/users", nil)
+		switch {
+		case i == 0 && err != nil:
+			t.Fatal(err)
+		case i > 0 && !errors.Is(err, middleware.ErrRateLimited):
+			t.Fatalf("call %d: Expected: %v, Got: %v", i+1, middleware.ErrRateLimited, err)
+		}
+	}
+
+	if got := breaker.State(ts.URL[len("http://"):]); got != CircuitClosed {
+		t.Errorf("Expected: %v, Got: %v", CircuitClosed, got)
+	}
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected 1 request, Got: %d", calls.Load())
+	}
+}
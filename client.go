@@ -6,22 +6,31 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/cenkalti/backoff/v4"
 	"github.com/reactivex/rxgo/v2"
+	"github.com/samber/lo"
 	"io"
 	"net/http"
 	"net/url"
+	"reflect"
 	"strings"
 	"time"
 )
 
 const (
-	Get        = HTTPMethod("GET")
-	Post       = HTTPMethod("POST")
-	Put        = HTTPMethod("PUT")
-	Patch      = HTTPMethod("PATCH")
-	Delete     = HTTPMethod("DELETE")
-	ConTimeOut = 15 * time.Second
+	Get             = HTTPMethod("GET")
+	Post            = HTTPMethod("POST")
+	Put             = HTTPMethod("PUT")
+	Patch           = HTTPMethod("PATCH")
+	Delete          = HTTPMethod("DELETE")
+	Head            = HTTPMethod("HEAD")
+	Options         = HTTPMethod("OPTIONS")
+	ContentTypeForm = "application/x-www-form-urlencoded"
+	ConTimeOut      = 15 * time.Second
+	DeadLine        = 5 * time.Second
+	RetryInterval   = 150 * time.Millisecond
+	MaxRetries      = 3
+	MaxRetryAfter   = time.Minute
+	MaxErrorBody    = 64 << 10
 )
 
 var (
@@ -38,6 +47,7 @@ var (
 	ErrBodyUnknownKey           = rokuErr("unknown key in the body")
 	ErrBodySizeLimit            = rokuErr("body size limit exceeded")
 	ErrBodyValue                = rokuErr("body must contain a single JSON value")
+	ErrBadEndpoint              = rokuErr("endpoint could not be resolved against the base URL")
 )
 
 type (
@@ -59,15 +69,40 @@ type (
 
 	HTTPMethod string
 
+	Client struct {
+		settings settings
+	}
+
 	Envelope[T ResI] struct {
 		Body *T
+		// UnknownFields lists the paths of the body fields missing from T when
+		// decoding with the Report strictness.
+		UnknownFields []string
 		*http.Response
 	}
 
 	rokuErr string
 
+	// ErrInvalidHTTPStatus reports a response rejected by the status code
+	// validator. Its headers and body are captured when it is created, so it
+	// can be inspected any number of times, and the connection is released.
 	ErrInvalidHTTPStatus struct {
 		Res *http.Response
+		// Method and URL are those of the request.
+		Method HTTPMethod
+		URL    string
+		// Attempt is the number of the attempt that got the response,
+		// starting at 1.
+		Attempt int
+		Header  http.Header
+		// Body holds the response body, up to the max response size or
+		// MaxErrorBody bytes, whichever is lower.
+		Body []byte
+		// BodyErr reports why Body is incomplete, e.g. ErrBodySizeLimit.
+		BodyErr error
+		// Problem holds the problem details of an application/problem+json
+		// body. errors.As finds it.
+		Problem *ProblemDetails
 	}
 
 	ErrDesc struct {
@@ -98,25 +133,45 @@ func newErrDesc(statusCode int, statusDesc string, message string) *ErrDesc {
 	return &errParams
 }
 
-func (e ErrInvalidHTTPStatus) Error() string {
-	if e.Res == nil {
-		return ""
+// newErrInvalidHTTPStatus captures the headers and at most limit bytes of the
+// body of res, and closes it.
+func newErrInvalidHTTPStatus(ctx context.Context, req *http.Request, res *http.Response, limit int64) ErrInvalidHTTPStatus {
+	errHTTP := ErrInvalidHTTPStatus{
+		Res:     res,
+		Method:  HTTPMethod(req.Method),
+		URL:     req.URL.String(),
+		Attempt: attemptFrom(ctx),
+		Header:  res.Header.Clone(),
 	}
 
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			return
-		}
-	}(e.Res.Body)
+	if res.Body == nil {
+		return errHTTP
+	}
+	defer res.Body.Close()
+
+	data, err := readBody(io.LimitReader(res.Body, limit+1))
+	switch {
+	case err != nil:
+		errHTTP.BodyErr = err
+	case int64(len(data)) > limit:
+		data = data[:limit]
+		errHTTP.BodyErr = bodyErr(&http.MaxBytesError{Limit: limit})
+	default:
+		errHTTP.Problem = parseProblem(res, data)
+	}
 
-	msg, err := io.ReadAll(e.Res.Body)
-	if err != nil {
+	errHTTP.Body = data
+	res.Body = io.NopCloser(bytes.NewReader(data))
+
+	return errHTTP
+}
+
+func (e ErrInvalidHTTPStatus) Error() string {
+	if e.Res == nil {
 		return ""
 	}
 
-	errDesc := newErrDesc(e.Res.StatusCode, e.Res.Status, string(msg))
-	errDescJSON, err := json.Marshal(errDesc)
+	errDescJSON, err := json.Marshal(e.desc())
 	if err != nil {
 		return ""
 	}
@@ -124,16 +179,32 @@ func (e ErrInvalidHTTPStatus) Error() string {
 	return string(errDescJSON)
 }
 
+// Unwrap returns the problem details of the response, if any.
+func (e ErrInvalidHTTPStatus) Unwrap() error {
+	if e.Problem == nil {
+		return nil
+	}
+	return e.Problem
+}
+
 func (e ErrInvalidHTTPStatus) UnmarshalError() (ErrDesc, error) {
-	var errDesc ErrDesc
+	if e.Res == nil {
+		return ErrDesc{}, ErrNilValue
+	}
 
-	reader := strings.NewReader(e.Error())
-	err := ReadJSON(reader, &errDesc)
-	if err != nil {
-		return ErrDesc{}, err
+	return e.desc(), nil
+}
+
+// desc describes the response with its captured body, even when BodyErr
+// reports that it was truncated. Only a body that could not be read at all is
+// described by BodyErr.
+func (e ErrInvalidHTTPStatus) desc() ErrDesc {
+	msg := string(e.Body)
+	if len(e.Body) == 0 && e.BodyErr != nil {
+		msg = e.BodyErr.Error()
 	}
 
-	return errDesc, nil
+	return *newErrDesc(e.Res.StatusCode, e.Res.Status, msg)
 }
 
 func GetErrorDesc(errHTTP ErrInvalidHTTPStatus) ErrDesc {
@@ -158,6 +229,37 @@ func NewHTTPClient(
 	return &httpClient
 }
 
+func NewClient(opts ...Option) *Client {
+	client := Client{
+		settings: newSettings(opts...),
+	}
+	return &client
+}
+
+func Send[T ReqI, U ResI](
+	ctx context.Context,
+	client *Client,
+	method HTTPMethod,
+	endpoint string,
+	request *T,
+	opts ...Option,
+) (*Envelope[U], error) {
+	s := client.settings.with(opts...)
+	return fetch[T, U](ctx, &s, method, endpoint, request)
+}
+
+func SendRx[T ReqI, U ResI](
+	ctx context.Context,
+	client *Client,
+	method HTTPMethod,
+	endpoint string,
+	request *T,
+	opts ...Option,
+) rxgo.Observable {
+	s := client.settings.with(opts...)
+	return fetchRx[T, U](ctx, &s, method, endpoint, request)
+}
+
 func FetchRx[T ReqI, U ResI](
 	ctx context.Context,
 	client *http.Client,
@@ -170,97 +272,214 @@ func FetchRx[T ReqI, U ResI](
 	backoffRetries uint64,
 	statusCodeValidator ...func(res *http.Response) bool,
 ) rxgo.Observable {
-	backOffCfg := backoff.NewExponentialBackOff()
-	backOffCfg.InitialInterval = backoffInterval
+	s := newSettings(
+		WithHTTPClient(client),
+		WithHeaders(headers),
+		WithDeadline(deadline),
+		WithRetries(backoffInterval, backoffRetries),
+		WithStatusCodeValidator(statusCodeValidator...),
+	)
+	return fetchRx[T, U](ctx, &s, method, endpoint, request)
+}
 
-	var validator func(res *http.Response) bool
+func Fetch[T ReqI, U ResI](
+	ctx context.Context,
+	client *http.Client,
+	method HTTPMethod,
+	endpoint string,
+	request *T,
+	headers map[string]string,
+	deadline time.Duration,
+	statusCodeValidator ...func(res *http.Response) bool,
+) (*Envelope[U], error) {
+	s := newSettings(
+		WithHTTPClient(client),
+		WithHeaders(headers),
+		WithDeadline(deadline),
+		WithStatusCodeValidator(statusCodeValidator...),
+	)
+	return fetch[T, U](ctx, &s, method, endpoint, request)
+}
 
-	switch statusCodeValidator {
-	case nil:
-		validator = defaultInvalidStatusCodeValidator
-	default:
-		validator = statusCodeValidator[0]
-	}
+func fetchRx[T ReqI, U ResI](
+	ctx context.Context,
+	s *settings,
+	method HTTPMethod,
+	endpoint string,
+	request *T,
+) rxgo.Observable {
+	return retryRx(ctx, s, method, endpoint, func(ctx context.Context, call *settings) (*Envelope[U], error) {
+		return fetch[T, U](ctx, call, method, endpoint, request)
+	})
+}
 
+// retryRx emits the envelope returned by attempt, retrying it as configured
+// by s.
+func retryRx[U ResI](
+	ctx context.Context,
+	s *settings,
+	method HTTPMethod,
+	endpoint string,
+	attempt func(ctx context.Context, call *settings) (*Envelope[U], error),
+) rxgo.Observable {
 	return rxgo.Defer([]rxgo.Producer{
 		func(_ context.Context, next chan<- rxgo.Item) {
-			res, err := Fetch[T, U](
-				ctx,
-				client,
-				method,
-				endpoint,
-				request,
-				headers,
-				deadline,
-				validator,
-			)
+			var res *Envelope[U]
+
+			if s.retryPolicyErr != nil {
+				next <- rxgo.Error(s.retryPolicyErr)
+				return
+			}
+
+			call, err := s.forMethod(method)
 			if err != nil {
 				next <- rxgo.Error(err)
+				return
+			}
+
+			err = call.retry(ctx, call.host(endpoint), func(ctx context.Context) error {
+				var err error
+				res, err = attempt(ctx, &call)
+				return err
+			})
+			if err != nil {
+				next <- rxgo.Error(err)
+				return
 			}
 			next <- rxgo.Of(res)
 		},
-	},
-	).BackOffRetry(
-		backoff.WithMaxRetries(backOffCfg, backoffRetries),
-	)
+	})
 }
 
-func Fetch[T ReqI, U ResI](
+func fetch[T ReqI, U ResI](
 	ctx context.Context,
-	client *http.Client,
+	s *settings,
 	method HTTPMethod,
 	endpoint string,
 	request *T,
-	headers map[string]string,
-	deadline time.Duration,
-	statusCodeValidator ...func(res *http.Response) bool,
 ) (*Envelope[U], error) {
-	var body U
-	var httpResponse *http.Response
-	var data []byte
-	var err error
-	var validator func(res *http.Response) bool
-
-	switch statusCodeValidator {
-	case nil:
-		validator = defaultInvalidStatusCodeValidator
-	default:
-		validator = statusCodeValidator[0]
+	if s.hedger == nil || !isIdempotent(method) {
+		return fetchOnce[T, U](ctx, s, method, endpoint, request)
 	}
 
-	reader, err := buildReader[T](request)
+	return hedge(ctx, s.hedger, s.host(endpoint), func(ctx context.Context) (*Envelope[U], error) {
+		return fetchOnce[T, U](ctx, s, method, endpoint, request)
+	})
+}
+
+func fetchOnce[T ReqI, U ResI](
+	ctx context.Context,
+	s *settings,
+	method HTTPMethod,
+	endpoint string,
+	request *T,
+) (*Envelope[U], error) {
+	var body U
+
+	httpResponse, codec, err := roundTrip[T](ctx, s, method, endpoint, request)
 	if err != nil {
 		return nil, err
 	}
 
-	switch reader {
-	case nil:
-		httpResponse, err = httpCall(ctx, client, endpoint, headers, nil, deadline, method, validator)
+	if httpResponse.StatusCode != http.StatusNoContent {
+		data, err := readBody(httpResponse.Body)
 		if err != nil {
+			_ = httpResponse.Body.Close()
 			return nil, err
 		}
-	default:
-		httpResponse, err = httpCall(ctx, client, endpoint, headers, reader, deadline, method, validator)
+
+		unknownFields, err := s.decode(s.codecs.responseCodec(httpResponse, codec), httpResponse, data, &body)
 		if err != nil {
 			return nil, err
 		}
+
+		env := newResponse[U](&body, httpResponse)
+		env.UnknownFields = unknownFields
+		return env, nil
 	}
 
-	if httpResponse.StatusCode != http.StatusNoContent {
-		data, err = io.ReadAll(httpResponse.Body)
-		if err != nil {
-			return nil, err
-		}
+	_ = httpResponse.Body.Close()
 
-		err = ReadJSON(bytes.NewReader(data), &body)
-		if err != nil {
-			return nil, err
+	return newResponse[U](nil, httpResponse), nil
+}
+
+// roundTrip builds the request from the endpoint template and request value,
+// sends it, and returns the response with its body bounded by the configured
+// maximum size, along with the codec used to encode the request.
+func roundTrip[T ReqI](
+	ctx context.Context,
+	s *settings,
+	method HTTPMethod,
+	endpoint string,
+	request *T,
+) (*http.Response, Codec, error) {
+	template := endpoint
+
+	endpoint, hasPath, err := expandPath[T](endpoint, request, s.pathParams)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	endpoint, err = s.resolve(endpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	endpoint, hasQuery, err := withQuery[T](endpoint, request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	codec, err := s.codecs.lookup(s.mediaType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader, contentType, err := buildReader[T](request, codec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if (hasPath || hasQuery) && contentType == codec.MediaType() && !declaresBody(reflect.TypeOf(request)) {
+		reader, contentType = nil, ""
+	}
+
+	record, err := s.circuit(method, template)
+	if err != nil {
+		if closer, ok := reader.(io.Closer); ok {
+			_ = closer.Close()
 		}
+		return nil, nil, err
+	}
 
-		return newResponse[U](&body, httpResponse), nil
+	errorBodyLimit := int64(MaxErrorBody)
+	if s.maxResponseSize > 0 {
+		errorBodyLimit = min(s.maxResponseSize, MaxErrorBody)
 	}
 
-	return newResponse[U](nil, httpResponse), nil
+	httpResponse, err := do(
+		ctx,
+		s.httpClient(),
+		endpoint,
+		method,
+		withDefaultHeader(
+			withDefaultHeader(s.headers, "Content-Type", contentType),
+			"Accept",
+			s.codecs.accept(codec.MediaType()),
+		),
+		reader,
+		s.deadline,
+		s.validator,
+		errorBodyLimit,
+	)
+	record(ctx, err)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	limitBody(httpResponse, s.maxResponseSize)
+
+	return httpResponse, codec, nil
 }
 
 func httpCall(
@@ -273,7 +492,7 @@ func httpCall(
 	method HTTPMethod,
 	statusCodeValidator func(res *http.Response) bool,
 ) (*http.Response, error) {
-	return do(ctx, client, url, method, headers, body, deadline, statusCodeValidator)
+	return do(ctx, client, url, method, headers, body, deadline, statusCodeValidator, MaxErrorBody)
 }
 
 func do(
@@ -285,6 +504,7 @@ func do(
 	body io.Reader,
 	deadline time.Duration,
 	statusCodeValidator func(res *http.Response) bool,
+	errorBodyLimit int64,
 ) (*http.Response, error) {
 	var res *http.Response
 	var err error
@@ -306,6 +526,9 @@ func do(
 
 	request, err := http.NewRequestWithContext(withCancelCtx, string(method), url, body)
 	if err != nil {
+		if closer, ok := body.(io.Closer); ok {
+			_ = closer.Close()
+		}
 		return nil, err
 	}
 
@@ -323,7 +546,7 @@ func do(
 	}
 
 	if statusCodeValidator(res) {
-		return nil, ErrInvalidHTTPStatus{Res: res}
+		return nil, newErrInvalidHTTPStatus(ctx, request, res, errorBodyLimit)
 	}
 
 	return res, nil
@@ -334,11 +557,30 @@ func defaultInvalidStatusCodeValidator(response *http.Response) bool {
 	return (response.StatusCode/100)/4 == 1 || (response.StatusCode/100)/5 == 1
 }
 
+// withDefaultHeader adds the header key unless headers already set it.
+func withDefaultHeader(headers map[string]string, key string, value string) map[string]string {
+	if value == "" {
+		return headers
+	}
+
+	for k := range headers {
+		if http.CanonicalHeaderKey(k) == key {
+			return headers
+		}
+	}
+
+	return lo.Assign[string, string](headers, map[string]string{key: value})
+}
+
 func toBytesReader[T any](value *T) (*bytes.Reader, error) {
+	return encodeReader[T](JSONCodec{}, value)
+}
+
+func encodeReader[T any](codec Codec, value *T) (*bytes.Reader, error) {
 	if value == nil {
 		return nil, ErrNilValue
 	}
-	data, err := json.Marshal(value)
+	data, err := codec.Marshal(value)
 	if err != nil {
 		return nil, fmt.Errorf("%q: %w", err.Error(), ErrMarshallValue)
 	}
@@ -362,39 +604,17 @@ func To[T any](item rxgo.Item) (*T, error) {
 }
 
 func ReadJSON(body io.Reader, dst any) error {
+	return readJSON(body, dst, true)
+}
+
+func readJSON(body io.Reader, dst any, strict bool) error {
 	dec := json.NewDecoder(body)
-	dec.DisallowUnknownFields()
+	if strict {
+		dec.DisallowUnknownFields()
+	}
 
 	if err := dec.Decode(dst); err != nil {
-		var (
-			syntaxError         *json.SyntaxError
-			unmarshalTypeError  *json.UnmarshalTypeError
-			invalidUnmarshalErr *json.InvalidUnmarshalError
-			maxBytesErr         *http.MaxBytesError
-		)
-
-		switch {
-		case errors.As(err, &syntaxError):
-			return fmt.Errorf("%w: at character %d", ErrBadlyJSON, syntaxError.Offset)
-		case errors.Is(err, io.ErrUnexpectedEOF):
-			return ErrBadlyJSON
-		case errors.As(err, &unmarshalTypeError):
-			if unmarshalTypeError.Field != "" {
-				return fmt.Errorf("%w for field %q", ErrBadJSONType, unmarshalTypeError.Field)
-			}
-			return fmt.Errorf("%w at character %d", ErrBadJSONType, unmarshalTypeError.Offset)
-		case errors.Is(err, io.EOF):
-			return ErrEmptyBody
-		case strings.HasPrefix(err.Error(), "json: unknown field "):
-			fieldName := strings.TrimPrefix(err.Error(), "json: unknown field ")
-			return fmt.Errorf("%w %s", ErrBodyUnknownKey, fieldName)
-		case errors.As(err, &maxBytesErr):
-			return fmt.Errorf("%w. Max size is %d bytes", ErrBodySizeLimit, maxBytesErr.Limit)
-		case errors.As(err, &invalidUnmarshalErr):
-			panic(err)
-		default:
-			return err
-		}
+		return jsonErr(err)
 	}
 
 	err := dec.Decode(&struct{}{})
@@ -405,21 +625,71 @@ func ReadJSON(body io.Reader, dst any) error {
 	return nil
 }
 
-func buildReader[T ReqI](request *T) (*bytes.Reader, error) {
-	if reqURL, ok := any(request).(ReqURLI); ok {
-		values := reqURL.GetURLValues()
-		return bytes.NewReader([]byte(values.Encode())), nil
+// jsonErr maps the errors of json.Decoder.Decode to roku errors.
+func jsonErr(err error) error {
+	var (
+		syntaxError         *json.SyntaxError
+		unmarshalTypeError  *json.UnmarshalTypeError
+		invalidUnmarshalErr *json.InvalidUnmarshalError
+		maxBytesErr         *http.MaxBytesError
+	)
+
+	switch {
+	case errors.As(err, &syntaxError):
+		return fmt.Errorf("%w: at character %d", ErrBadlyJSON, syntaxError.Offset)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrBadlyJSON
+	case errors.As(err, &unmarshalTypeError):
+		if unmarshalTypeError.Field != "" {
+			return fmt.Errorf("%w for field %q", ErrBadJSONType, unmarshalTypeError.Field)
+		}
+		return fmt.Errorf("%w at character %d", ErrBadJSONType, unmarshalTypeError.Offset)
+	case errors.Is(err, io.EOF):
+		return ErrEmptyBody
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		fieldName := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		return fmt.Errorf("%w %s", ErrBodyUnknownKey, fieldName)
+	case errors.As(err, &maxBytesErr):
+		return fmt.Errorf("%w. Max size is %d bytes", ErrBodySizeLimit, maxBytesErr.Limit)
+	case errors.As(err, &invalidUnmarshalErr):
+		panic(err)
+	default:
+		return err
 	}
+}
 
-	reader, err := toBytesReader[T](request)
+func buildReader[T ReqI](request *T, codec Codec) (io.Reader, string, error) {
+	if request == nil {
+		return nil, "", nil
+	}
+
+	if reqMultipart, ok := any(request).(ReqMultipartI); ok {
+		parts, err := reqMultipart.GetParts()
+		if err != nil {
+			return nil, "", err
+		}
+		reader, contentType := multipartReader(parts)
+		return reader, contentType, nil
+	}
+
+	if isForm[T](request) {
+		values, err := formValues[T](request)
+		if err != nil {
+			return nil, "", err
+		}
+		return strings.NewReader(values.Encode()), ContentTypeForm, nil
+	}
+
+	reader, err := encodeReader[T](codec, request)
 	switch err {
 	case nil:
 		break
 	default:
 		if !errors.Is(err, ErrNilValue) {
-			return nil, err
+			return nil, "", err
 		}
+		return nil, "", nil
 	}
 
-	return reader, nil
+	return reader, codec.MediaType(), nil
 }
@@ -1044,6 +1044,76 @@ func TestFetchingWithNonNilBodyReturnsNotFoundErr(t *testing.T) {
 	}
 }
 
+func TestFetchingWithFailingServerCapturesErrorResponse(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		errorResponse(w, r, http.StatusServiceUnavailable, "try later")
+	})
+	defer ts.Close()
+
+	client := NewClient(WithHTTPClient(httpClient), WithBaseURL(ts.URL), WithRetries(time.Millisecond, 2))
+
+	ch := SendRx[NoReq, getUserEnvV1Res](context.Background(), client, Get, "/users", nil).Observe()
+
+	_, err := To[Envelope[getUserEnvV1Res]](<-ch)
+
+	var errHTTP ErrInvalidHTTPStatus
+	if !errors.As(err, &errHTTP) {
+		t.Fatalf("wrong error: %v", err)
+	}
+
+	if first, second := errHTTP.Error(), err.Error(); first == "" || first != second {
+		t.Errorf("Expected the same message twice, Got: %q and %q", first, second)
+	}
+
+	errDesc, err := errHTTP.UnmarshalError()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := ErrDesc{
+		StatusCode: http.StatusServiceUnavailable,
+		Status:     "503 Service Unavailable",
+		ErrMessage: "{\n\t\"error\": \"try later\"\n}\n",
+	}
+	if errDesc != want {
+		t.Errorf("Expected: %+v, Got: %+v", want, errDesc)
+	}
+
+	if errHTTP.Method != Get || errHTTP.URL != ts.URL+"/users" || errHTTP.Attempt != 3 {
+		t.Errorf("Expected GET %s on attempt 3, Got: %s %s on attempt %d", ts.URL+"/users", errHTTP.Method, errHTTP.URL, errHTTP.Attempt)
+	}
+
+	if errHTTP.Header.Get("X-Request-Id") != "req-1" {
+		t.Errorf("Expected the response headers, Got: %v", errHTTP.Header)
+	}
+}
+
+func TestFetchingWithLargeErrorBodyCapturesItsStart(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write(bytes.Repeat([]byte("x"), MaxErrorBody+10))
+	})
+	defer ts.Close()
+
+	_, err := Fetch[NoReq, getUserEnvV1Res](context.Background(), httpClient, Get, ts.URL, nil, nil, time.Second)
+
+	var errHTTP ErrInvalidHTTPStatus
+	if !errors.As(err, &errHTTP) {
+		t.Fatalf("wrong error: %v", err)
+	}
+
+	if len(errHTTP.Body) != MaxErrorBody || !errors.Is(errHTTP.BodyErr, ErrBodySizeLimit) {
+		t.Errorf("Expected %d bytes and %v, Got: %d bytes and %v", MaxErrorBody, ErrBodySizeLimit, len(errHTTP.Body), errHTTP.BodyErr)
+	}
+
+	if got := GetErrorDesc(errHTTP).ErrMessage; got != string(errHTTP.Body) {
+		t.Errorf("Expected the captured body in the message, Got: %d bytes", len(got))
+	}
+}
+
 func getUserHandler(w http.ResponseWriter, r *http.Request) {
 	env := envelope{
 		"user": userRes,
@@ -0,0 +1,167 @@
+package roku
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+const (
+	MediaTypeJSON = "application/json"
+	MediaTypeXML  = "application/xml"
+)
+
+var (
+	ErrUnknownMediaType = rokuErr("no codec registered for the media type")
+	ErrDecodeBody       = rokuErr("body could not be decoded")
+)
+
+type (
+	// Codec encodes request bodies and decodes response bodies for a media
+	// type, e.g. application/json.
+	Codec interface {
+		MediaType() string
+		Marshal(v any) ([]byte, error)
+		Unmarshal(data []byte, v any) error
+	}
+
+	// LenientCodec is implemented by codecs able to decode bodies holding
+	// fields unknown to v. When report is true, it returns their paths.
+	LenientCodec interface {
+		UnmarshalLenient(data []byte, v any, report bool) ([]string, error)
+	}
+
+	JSONCodec struct{}
+
+	XMLCodec struct{}
+
+	codecs map[string]Codec
+)
+
+func (JSONCodec) MediaType() string {
+	return MediaTypeJSON
+}
+
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v any) error {
+	return ReadJSON(bytes.NewReader(data), v)
+}
+
+func (JSONCodec) UnmarshalLenient(data []byte, v any, report bool) ([]string, error) {
+	err := readJSON(bytes.NewReader(data), v, false)
+	if err != nil || !report {
+		return nil, err
+	}
+	return unknownJSONFields(data, reflect.TypeOf(v))
+}
+
+func (XMLCodec) MediaType() string {
+	return MediaTypeXML
+}
+
+func (XMLCodec) Marshal(v any) ([]byte, error) {
+	return xml.Marshal(v)
+}
+
+func (XMLCodec) Unmarshal(data []byte, v any) error {
+	err := xml.Unmarshal(data, v)
+	switch {
+	case err == nil:
+		return nil
+	case err == io.EOF:
+		return ErrEmptyBody
+	default:
+		return fmt.Errorf("%q: %w", err.Error(), ErrDecodeBody)
+	}
+}
+
+func newCodecs() codecs {
+	return codecs{
+		MediaTypeJSON: JSONCodec{},
+		MediaTypeXML:  XMLCodec{},
+		"text/xml":    XMLCodec{},
+	}
+}
+
+// with returns a copy of c with codec registered under mediaTypes, or under
+// its own media type when none is given.
+func (c codecs) with(codec Codec, mediaTypes ...string) codecs {
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{codec.MediaType()}
+	}
+
+	registry := make(codecs, len(c)+len(mediaTypes))
+	for k, v := range c {
+		registry[k] = v
+	}
+	for _, mediaType := range mediaTypes {
+		registry[normalizeMediaType(mediaType)] = codec
+	}
+	return registry
+}
+
+// lookup returns the codec registered for contentType. Structured syntax
+// suffixes such as application/problem+json fall back to the codec of the
+// base format.
+func (c codecs) lookup(contentType string) (Codec, error) {
+	mediaType := normalizeMediaType(contentType)
+
+	if codec, ok := c[mediaType]; ok {
+		return codec, nil
+	}
+
+	if i := strings.LastIndexByte(mediaType, '+'); i >= 0 {
+		if codec, ok := c["application/"+mediaType[i+1:]]; ok {
+			return codec, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w %q", ErrUnknownMediaType, mediaType)
+}
+
+// accept lists the registered media types, preferred first.
+func (c codecs) accept(preferred string) string {
+	mediaTypes := make([]string, 0, len(c))
+	for mediaType := range c {
+		if mediaType != preferred {
+			mediaTypes = append(mediaTypes, mediaType+";q=0.9")
+		}
+	}
+	slices.Sort(mediaTypes)
+
+	return strings.Join(append([]string{preferred}, mediaTypes...), ", ")
+}
+
+// responseCodec picks the codec matching the response Content-Type. Responses
+// without one, or with an unregistered one, are decoded with fallback.
+func (c codecs) responseCodec(res *http.Response, fallback Codec) Codec {
+	contentType := res.Header.Get("Content-Type")
+	if contentType == "" {
+		return fallback
+	}
+
+	codec, err := c.lookup(contentType)
+	if err != nil {
+		return fallback
+	}
+
+	return codec
+}
+
+func normalizeMediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mediaType
+}
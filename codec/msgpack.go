@@ -0,0 +1,23 @@
+package codec
+
+import (
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	MediaTypeMsgPack = "application/msgpack"
+)
+
+type MsgPack struct{}
+
+func (MsgPack) MediaType() string {
+	return MediaTypeMsgPack
+}
+
+func (MsgPack) Marshal(v any) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+func (MsgPack) Unmarshal(data []byte, v any) error {
+	return msgpack.Unmarshal(data, v)
+}
@@ -0,0 +1,61 @@
+package codec
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+type user struct {
+	Name  string   `msgpack:"name"`
+	Email string   `msgpack:"email"`
+	Tags  []string `msgpack:"tags"`
+}
+
+func TestMsgPackRoundTripsValues(t *testing.T) {
+	t.Parallel()
+
+	want := user{Name: "Adam Smith", Email: "adam.smith@hotmail.com", Tags: []string{"admin"}}
+
+	data, err := MsgPack{}.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got user
+	if err := (MsgPack{}).Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestMsgPackUnmarshalingInvalidDataFails(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input []byte
+		into  any
+	}{
+		"with truncated data": {input: []byte{0x82, 0xa4, 'n', 'a'}, into: &user{}},
+		"with wrong type":     {input: []byte{0xa3, 'a', 'b', 'c'}, into: new(int)},
+		"with non pointer":    {input: []byte{0x01}, into: 0},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			if err := (MsgPack{}).Unmarshal(tc.input, tc.into); err == nil {
+				t.Error("Expected an error, Got: nil")
+			}
+		})
+	}
+}
+
+func TestMsgPackMediaType(t *testing.T) {
+	t.Parallel()
+
+	if got := (MsgPack{}).MediaType(); got != MediaTypeMsgPack {
+		t.Errorf("Expected: %s, Got: %s", MediaTypeMsgPack, got)
+	}
+}
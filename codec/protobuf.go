@@ -0,0 +1,36 @@
+package codec
+
+import (
+	"errors"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	MediaTypeProtobuf = "application/x-protobuf"
+)
+
+var (
+	ErrNotProtoMessage = errors.New("value is not a proto.Message")
+)
+
+type Protobuf struct{}
+
+func (Protobuf) MediaType() string {
+	return MediaTypeProtobuf
+}
+
+func (Protobuf) Marshal(v any) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, ErrNotProtoMessage
+	}
+	return proto.Marshal(msg)
+}
+
+func (Protobuf) Unmarshal(data []byte, v any) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return ErrNotProtoMessage
+	}
+	return proto.Unmarshal(data, msg)
+}
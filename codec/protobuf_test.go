@@ -0,0 +1,76 @@
+package codec
+
+import (
+	"errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"testing"
+)
+
+func TestProtobufRoundTripsMessages(t *testing.T) {
+	t.Parallel()
+
+	want, err := structpb.NewStruct(map[string]any{
+		"name":  "Adam Smith",
+		"email": "adam.smith@hotmail.com",
+		"tags":  []any{"admin"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := Protobuf{}.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := &structpb.Struct{}
+	if err := (Protobuf{}).Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !proto.Equal(want, got) {
+		t.Errorf("Expected: %v, Got: %v", want, got)
+	}
+}
+
+func TestProtobufWithoutProtoMessageFails(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input any
+	}{
+		"with struct": {input: struct{ Name string }{Name: "Adam Smith"}},
+		"with map":    {input: map[string]any{"name": "Adam Smith"}},
+		"with nil":    {input: nil},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			if _, err := (Protobuf{}).Marshal(tc.input); !errors.Is(err, ErrNotProtoMessage) {
+				t.Errorf("Expected: %v, Got: %v", ErrNotProtoMessage, err)
+			}
+
+			if err := (Protobuf{}).Unmarshal([]byte{}, tc.input); !errors.Is(err, ErrNotProtoMessage) {
+				t.Errorf("Expected: %v, Got: %v", ErrNotProtoMessage, err)
+			}
+		})
+	}
+}
+
+func TestProtobufUnmarshalingInvalidDataFails(t *testing.T) {
+	t.Parallel()
+
+	err := Protobuf{}.Unmarshal([]byte{0x0a, 0x05, 'n'}, &structpb.Struct{})
+	if err == nil || errors.Is(err, ErrNotProtoMessage) {
+		t.Errorf("Expected a decoding error, Got: %v", err)
+	}
+}
+
+func TestProtobufMediaType(t *testing.T) {
+	t.Parallel()
+
+	if got := (Protobuf{}).MediaType(); got != MediaTypeProtobuf {
+		t.Errorf("Expected: %s, Got: %s", MediaTypeProtobuf, got)
+	}
+}
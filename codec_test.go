@@ -0,0 +1,173 @@
+package roku
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"github.com/v8tix/roku/codec"
+	"github.com/vmihailenco/msgpack/v5"
+	"io"
+	"net/http"
+	"testing"
+)
+
+type (
+	negotiatedV1Req struct {
+		XMLName xml.Name `json:"-" xml:"user" msgpack:"-"`
+		Name    string   `json:"name" xml:"name" msgpack:"name"`
+	}
+
+	negotiatedV1Res struct {
+		XMLName     xml.Name `json:"-" xml:"echo" msgpack:"-"`
+		ContentType string   `json:"content_type" xml:"content_type" msgpack:"content_type"`
+		Accept      string   `json:"accept" xml:"accept" msgpack:"accept"`
+		Body        string   `json:"body" xml:"body" msgpack:"body"`
+	}
+)
+
+func (negotiatedV1Req) Req() {}
+
+func (negotiatedV1Res) Res() {}
+
+func TestSendingWithCodecsNegotiatesContentType(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(negotiationHandler)
+	defer ts.Close()
+
+	client := NewClient(
+		WithHTTPClient(httpClient),
+		WithBaseURL(ts.URL),
+		WithCodec(codec.MsgPack{}, codec.MediaTypeMsgPack, "application/x-msgpack"),
+	)
+	req := negotiatedV1Req{Name: "Adam Smith"}
+
+	cases := map[string]struct {
+		opts []Option
+		want negotiatedV1Res
+	}{
+		"with default codec": {
+			opts: nil,
+			want: negotiatedV1Res{
+				ContentType: MediaTypeJSON,
+				Accept:      "application/json, application/msgpack;q=0.9, application/x-msgpack;q=0.9, application/xml;q=0.9, text/xml;q=0.9",
+				Body:        `{"name":"Adam Smith"}`,
+			},
+		},
+		"with xml codec": {
+			opts: []Option{WithMediaType(MediaTypeXML)},
+			want: negotiatedV1Res{
+				ContentType: MediaTypeXML,
+				Accept:      "application/xml, application/json;q=0.9, application/msgpack;q=0.9, application/x-msgpack;q=0.9, text/xml;q=0.9",
+				Body:        "<user><name>Adam Smith</name></user>",
+			},
+		},
+		"with msgpack codec": {
+			opts: []Option{WithMediaType(codec.MediaTypeMsgPack)},
+			want: negotiatedV1Res{
+				ContentType: codec.MediaTypeMsgPack,
+				Accept:      "application/msgpack, application/json;q=0.9, application/x-msgpack;q=0.9, application/xml;q=0.9, text/xml;q=0.9",
+				Body:        "\x81\xa4name\xaaAdam Smith",
+			},
+		},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			got, err := Send[negotiatedV1Req, negotiatedV1Res](
+				context.Background(),
+				client,
+				Post,
+				"/echo",
+				&req,
+				tc.opts...,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got.Body.XMLName = xml.Name{}
+			if !(cmp.Equal(tc.want, *got.Body)) {
+				t.Error(cmp.Diff(tc.want, *got.Body))
+			}
+		})
+	}
+}
+
+func TestSendingWithUnknownMediaTypeReturnsError(t *testing.T) {
+	t.Parallel()
+
+	client := NewClient(WithHTTPClient(httpClient), WithMediaType("application/yaml"))
+	req := negotiatedV1Req{Name: "Adam Smith"}
+
+	_, err := Send[negotiatedV1Req, negotiatedV1Res](context.Background(), client, Post, "http://localhost", &req)
+	if !errors.Is(err, ErrUnknownMediaType) {
+		t.Errorf("wrong error: %v", err)
+	}
+}
+
+func TestLookingUpCodecWithStructuredSuffixReturnsBaseCodec(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input string
+		want  Codec
+	}{
+		"with problem json":    {input: "application/problem+json; charset=utf-8", want: JSONCodec{}},
+		"with atom xml":        {input: "application/atom+xml", want: XMLCodec{}},
+		"with upper case json": {input: "Application/JSON", want: JSONCodec{}},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			got, err := newCodecs().lookup(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tc.want {
+				t.Errorf("Expected codec: %T, Got: %T", tc.want, got)
+			}
+		})
+	}
+}
+
+func negotiationHandler(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		serverErrorResponse(w, r)
+		return
+	}
+
+	res := negotiatedV1Res{
+		ContentType: r.Header.Get("Content-Type"),
+		Accept:      r.Header.Get("Accept"),
+		Body:        string(body),
+	}
+
+	var data []byte
+	switch r.Header.Get("Content-Type") {
+	case MediaTypeXML:
+		data, err = xml.Marshal(res)
+	case codec.MediaTypeMsgPack:
+		data, err = msgpack.Marshal(res)
+	default:
+		err = write(w, http.StatusOK, envelope{
+			"content_type": res.ContentType,
+			"accept":       res.Accept,
+			"body":         res.Body,
+		}, nil)
+		if err != nil {
+			serverErrorResponse(w, r)
+		}
+		return
+	}
+	if err != nil {
+		serverErrorResponse(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,37 @@
+package roku
+
+import (
+	"net/url"
+	"reflect"
+)
+
+const formTag = "form"
+
+// isForm reports whether request is sent as an
+// application/x-www-form-urlencoded body, either because it implements ReqURLI
+// or because it declares `form` struct tags.
+func isForm[T ReqI](request *T) bool {
+	if _, ok := any(request).(ReqURLI); ok {
+		return true
+	}
+	return declaresTag(reflect.TypeOf(request), formTag)
+}
+
+// formValues merges the values of the `form` struct tags on request with the
+// ones returned by ReqURLI.
+func formValues[T ReqI](request *T) (url.Values, error) {
+	values, err := encodeValues(request, formTag)
+	if err != nil {
+		return nil, err
+	}
+
+	if reqURL, ok := any(request).(ReqURLI); ok {
+		for k, vs := range reqURL.GetURLValues() {
+			for _, v := range vs {
+				values.Add(k, v)
+			}
+		}
+	}
+
+	return values, nil
+}
@@ -0,0 +1,111 @@
+package roku
+
+import (
+	"context"
+	"github.com/google/go-cmp/cmp"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+type (
+	tokenV1Req struct {
+		GrantType string   `form:"grant_type"`
+		ClientID  string   `form:"client_id"`
+		Scopes    []string `form:"scope,omitempty"`
+	}
+
+	legacyFormV1Req struct {
+		values url.Values
+	}
+
+	tokenV1Res struct {
+		AccessToken string     `json:"access_token,omitempty"`
+		ContentType string     `json:"content_type,omitempty"`
+		Form        url.Values `json:"form,omitempty"`
+	}
+)
+
+func (tokenV1Req) Req() {}
+
+func (legacyFormV1Req) Req() {}
+
+func (l legacyFormV1Req) GetURLValues() url.Values {
+	return l.values
+}
+
+func (tokenV1Res) Res() {}
+
+func TestSendingWithFormRequestSendsFormBody(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(tokenHandler)
+	defer ts.Close()
+
+	client := NewClient(WithHTTPClient(httpClient), WithBaseURL(ts.URL))
+
+	cases := map[string]struct {
+		send func() (*Envelope[tokenV1Res], error)
+		want url.Values
+	}{
+		"with form tags": {
+			send: func() (*Envelope[tokenV1Res], error) {
+				req := tokenV1Req{GrantType: "client_credentials", ClientID: "roku", Scopes: []string{"read", "write"}}
+				return Send[tokenV1Req, tokenV1Res](context.Background(), client, Post, "/token", &req)
+			},
+			want: url.Values{
+				"grant_type": {"client_credentials"},
+				"client_id":  {"roku"},
+				"scope":      {"read", "write"},
+			},
+		},
+		"with url values": {
+			send: func() (*Envelope[tokenV1Res], error) {
+				req := legacyFormV1Req{values: url.Values{"grant_type": {"password"}}}
+				return Send[legacyFormV1Req, tokenV1Res](context.Background(), client, Post, "/token", &req)
+			},
+			want: url.Values{
+				"grant_type": {"password"},
+			},
+		},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			got, err := tc.send()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got.Body.ContentType != ContentTypeForm {
+				t.Errorf("Expected content type: %s, Got: %s", ContentTypeForm, got.Body.ContentType)
+			}
+
+			if got.Body.AccessToken != "token" {
+				t.Errorf("Expected access token: token, Got: %s", got.Body.AccessToken)
+			}
+
+			if !(cmp.Equal(tc.want, got.Body.Form)) {
+				t.Error(cmp.Diff(tc.want, got.Body.Form))
+			}
+		})
+	}
+}
+
+func tokenHandler(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		errorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	env := envelope{
+		"access_token": "token",
+		"content_type": r.Header.Get("Content-Type"),
+		"form":         r.PostForm,
+	}
+
+	err = write(w, http.StatusOK, env, nil)
+	if err != nil {
+		serverErrorResponse(w, r)
+	}
+}
@@ -0,0 +1,149 @@
+package roku
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	hedgeSamples    = 128
+	hedgeMinSamples = 20
+)
+
+var (
+	ErrHedger = rokuErr("invalid hedging settings")
+)
+
+type (
+	// HedgeSettings configures NewHedger.
+	HedgeSettings struct {
+		// Delay is how long a call waits for its response before a second,
+		// identical request is sent. With a Percentile, it is only used until
+		// enough latencies were observed.
+		Delay time.Duration
+		// Percentile, between 0 and 1, e.g. 0.95, derives the delay from the
+		// latencies of the last successful calls. Zero keeps Delay fixed.
+		Percentile float64
+		// Budget bounds the hedged requests sent to each host. Nil leaves
+		// them unbounded.
+		Budget *RetryBudget
+	}
+
+	// Hedger sends a second request when the first one of an idempotent call
+	// is slow, and keeps the response that arrives first.
+	Hedger struct {
+		settings  HedgeSettings
+		mu        sync.Mutex
+		latencies []time.Duration
+		next      int
+	}
+
+	hedgeResult[R any] struct {
+		value R
+		err   error
+	}
+)
+
+// NewHedger returns a Hedger for settings, or an ErrHedger error when they
+// set neither a Delay nor a Percentile, or one of them is out of range.
+func NewHedger(settings HedgeSettings) (*Hedger, error) {
+	switch {
+	case settings.Delay < 0:
+		return nil, fmt.Errorf("%w: negative delay %v", ErrHedger, settings.Delay)
+	case settings.Percentile < 0 || settings.Percentile > 1:
+		return nil, fmt.Errorf("%w: percentile %v is not between 0 and 1", ErrHedger, settings.Percentile)
+	case settings.Delay == 0 && settings.Percentile == 0:
+		return nil, fmt.Errorf("%w: neither a delay nor a percentile", ErrHedger)
+	}
+
+	hedger := Hedger{
+		settings:  settings,
+		latencies: make([]time.Duration, 0, hedgeSamples),
+	}
+	return &hedger, nil
+}
+
+// delay returns how long to wait before hedging, and false when no delay is
+// known yet.
+func (h *Hedger) delay() (time.Duration, bool) {
+	if h.settings.Percentile == 0 {
+		return h.settings.Delay, true
+	}
+
+	h.mu.Lock()
+	if len(h.latencies) < hedgeMinSamples {
+		h.mu.Unlock()
+		return h.settings.Delay, h.settings.Delay > 0
+	}
+	latencies := slices.Clone(h.latencies)
+	h.mu.Unlock()
+
+	slices.Sort(latencies)
+	i := int(h.settings.Percentile * float64(len(latencies)-1))
+	return latencies[i], true
+}
+
+// observe records the latency of a successful request.
+func (h *Hedger) observe(latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.latencies) < hedgeSamples {
+		h.latencies = append(h.latencies, latency)
+		return
+	}
+
+	h.latencies[h.next] = latency
+	h.next = (h.next + 1) % hedgeSamples
+}
+
+// hedge runs attempt and, when it is still running after the hedging delay
+// and the budget of host allows it, runs it a second time. It returns the
+// first success and cancels the other attempt, or the last error when both
+// fail.
+func hedge[R any](ctx context.Context, h *Hedger, host string, attempt func(ctx context.Context) (R, error)) (R, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult[R], 2)
+	launch := func() {
+		go func() {
+			start := time.Now()
+			value, err := attempt(ctx)
+			if err == nil {
+				h.observe(time.Since(start))
+			}
+			results <- hedgeResult[R]{value: value, err: err}
+		}()
+	}
+
+	h.settings.Budget.deposit(host, time.Now())
+	launch()
+	pending := 1
+
+	var hedgeAfter <-chan time.Time
+	if delay, ok := h.delay(); ok {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		hedgeAfter = timer.C
+	}
+
+	for {
+		select {
+		case <-hedgeAfter:
+			hedgeAfter = nil
+			if h.settings.Budget.withdraw(host, time.Now()) {
+				launch()
+				pending++
+			}
+		case result := <-results:
+			pending--
+			if result.err == nil || pending == 0 {
+				return result.value, result.err
+			}
+		}
+	}
+}
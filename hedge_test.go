@@ -0,0 +1,149 @@
+package roku
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchingWithHedgingTakesFirstResponse(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	canceled := make(chan struct{})
+	ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+				close(canceled)
+				return
+			case <-time.After(2 * time.Second):
+			}
+		}
+		getUserHandler(w, r)
+	})
+	defer ts.Close()
+
+	hedger, err := NewHedger(HedgeSettings{Delay: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClient(WithHTTPClient(httpClient), WithBaseURL(ts.URL), WithHedging(hedger))
+
+	start := time.Now()
+	ch := SendRx[NoReq, getUserEnvV1Res](context.Background(), client, Get, "/users", nil).Observe()
+
+	got, err := To[Envelope[getUserEnvV1Res]](<-ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the hedged request to win, Got: %v", elapsed)
+	}
+
+	if got.Body.User != userEnvRes.User {
+		t.Errorf("Expected: %v, Got: %v", userEnvRes.User, got.Body.User)
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("Expected the slow request to be canceled")
+	}
+}
+
+func TestFetchingWithHedgingSkipsNonIdempotentCallsAndSpentBudget(t *testing.T) {
+	t.Parallel()
+
+	noBudget, err := NewRetryBudget(0, 0, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		method HTTPMethod
+		budget *RetryBudget
+		want   int32
+	}{
+		"with get":          {method: Get, want: 2},
+		"with post":         {method: Post, want: 1},
+		"with spent budget": {method: Get, budget: noBudget, want: 1},
+	}
+
+	for input, tc := range cases {
+		tc := tc
+		t.Run(input, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+			ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				time.Sleep(50 * time.Millisecond)
+				getUserHandler(w, r)
+			})
+			defer ts.Close()
+
+			hedger, err := NewHedger(HedgeSettings{Delay: 10 * time.Millisecond, Budget: tc.budget})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := NewClient(WithHTTPClient(httpClient), WithBaseURL(ts.URL), WithHedging(hedger))
+
+			if _, err := Send[NoReq, getUserEnvV1Res](context.Background(), client, tc.method, "/users", nil); err != nil {
+				t.Fatal(err)
+			}
+
+			time.Sleep(100 * time.Millisecond)
+			if calls.Load() != tc.want {
+				t.Errorf("Expected %d requests, Got: %d", tc.want, calls.Load())
+			}
+		})
+	}
+}
+
+func TestHedgingDelayFollowsObservedPercentile(t *testing.T) {
+	t.Parallel()
+
+	hedger, err := NewHedger(HedgeSettings{Percentile: 0.95})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := hedger.delay(); ok {
+		t.Error("Expected no delay before latencies are observed")
+	}
+
+	for i := 1; i <= 300; i++ {
+		hedger.observe(time.Duration(i%100+1) * time.Millisecond)
+	}
+
+	delay, ok := hedger.delay()
+	if !ok || delay < 90*time.Millisecond || delay > 100*time.Millisecond {
+		t.Errorf("Expected a delay near 95ms, Got: %v %t", delay, ok)
+	}
+}
+
+func TestBuildingHedgerValidatesIt(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]HedgeSettings{
+		"with negative delay":               {Delay: -time.Second},
+		"with percentile over one":          {Percentile: 1.5},
+		"with neither delay nor percentile": {},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			_, err := NewHedger(tc)
+			if !errors.Is(err, ErrHedger) {
+				t.Errorf("Expected: %v, Got: %v", ErrHedger, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package roku
+
+import (
+	"context"
+	"errors"
+	"github.com/reactivex/rxgo/v2"
+	"net/http"
+	"time"
+)
+
+type (
+	// HTTPError is an ErrInvalidHTTPStatus whose body was decoded into an E.
+	// When the body cannot be decoded, Decoded is nil, DecodeErr tells why,
+	// and the raw body is still available in Body.
+	HTTPError[E any] struct {
+		ErrInvalidHTTPStatus
+		Decoded   *E
+		DecodeErr error
+	}
+)
+
+// Unwrap returns the ErrInvalidHTTPStatus, so that errors.As finds it and the
+// problem details it holds.
+func (e HTTPError[E]) Unwrap() error {
+	return e.ErrInvalidHTTPStatus
+}
+
+// FetchE is Fetch returning an HTTPError[E] when the response status is
+// rejected.
+func FetchE[T ReqI, U ResI, E any](
+	ctx context.Context,
+	client *http.Client,
+	method HTTPMethod,
+	endpoint string,
+	request *T,
+	headers map[string]string,
+	deadline time.Duration,
+	statusCodeValidator ...func(res *http.Response) bool,
+) (*Envelope[U], error) {
+	s := newSettings(
+		WithHTTPClient(client),
+		WithHeaders(headers),
+		WithDeadline(deadline),
+		WithStatusCodeValidator(statusCodeValidator...),
+	)
+	return fetchE[T, U, E](ctx, &s, method, endpoint, request)
+}
+
+// FetchRxE is FetchRx emitting an HTTPError[E] when the response status is
+// rejected.
+func FetchRxE[T ReqI, U ResI, E any](
+	ctx context.Context,
+	client *http.Client,
+	method HTTPMethod,
+	endpoint string,
+	request *T,
+	headers map[string]string,
+	deadline time.Duration,
+	backoffInterval time.Duration,
+	backoffRetries uint64,
+	statusCodeValidator ...func(res *http.Response) bool,
+) rxgo.Observable {
+	s := newSettings(
+		WithHTTPClient(client),
+		WithHeaders(headers),
+		WithDeadline(deadline),
+		WithRetries(backoffInterval, backoffRetries),
+		WithStatusCodeValidator(statusCodeValidator...),
+	)
+	return fetchRxE[T, U, E](ctx, &s, method, endpoint, request)
+}
+
+func SendE[T ReqI, U ResI, E any](
+	ctx context.Context,
+	client *Client,
+	method HTTPMethod,
+	endpoint string,
+	request *T,
+	opts ...Option,
+) (*Envelope[U], error) {
+	s := client.settings.with(opts...)
+	return fetchE[T, U, E](ctx, &s, method, endpoint, request)
+}
+
+func SendRxE[T ReqI, U ResI, E any](
+	ctx context.Context,
+	client *Client,
+	method HTTPMethod,
+	endpoint string,
+	request *T,
+	opts ...Option,
+) rxgo.Observable {
+	s := client.settings.with(opts...)
+	return fetchRxE[T, U, E](ctx, &s, method, endpoint, request)
+}
+
+func fetchRxE[T ReqI, U ResI, E any](
+	ctx context.Context,
+	s *settings,
+	method HTTPMethod,
+	endpoint string,
+	request *T,
+) rxgo.Observable {
+	return retryRx(ctx, s, method, endpoint, func(ctx context.Context, call *settings) (*Envelope[U], error) {
+		return fetchE[T, U, E](ctx, call, method, endpoint, request)
+	})
+}
+
+func fetchE[T ReqI, U ResI, E any](
+	ctx context.Context,
+	s *settings,
+	method HTTPMethod,
+	endpoint string,
+	request *T,
+) (*Envelope[U], error) {
+	env, err := fetch[T, U](ctx, s, method, endpoint, request)
+	if err == nil {
+		return env, nil
+	}
+
+	var errHTTP ErrInvalidHTTPStatus
+	if !errors.As(err, &errHTTP) || errHTTP.Res == nil {
+		return nil, err
+	}
+
+	return nil, decodeHTTPError[E](s, errHTTP)
+}
+
+// decodeHTTPError decodes the captured body of errHTTP with the codec matching
+// its Content-Type, ignoring unknown fields.
+func decodeHTTPError[E any](s *settings, errHTTP ErrInvalidHTTPStatus) HTTPError[E] {
+	typed := HTTPError[E]{ErrInvalidHTTPStatus: errHTTP}
+	if errHTTP.BodyErr != nil {
+		typed.DecodeErr = errHTTP.BodyErr
+		return typed
+	}
+
+	codec := s.codecs.responseCodec(errHTTP.Res, JSONCodec{})
+
+	var decoded E
+	var err error
+	if lenient, ok := codec.(LenientCodec); ok {
+		_, err = lenient.UnmarshalLenient(errHTTP.Body, &decoded, false)
+	} else {
+		err = codec.Unmarshal(errHTTP.Body, &decoded)
+	}
+
+	if err != nil {
+		typed.DecodeErr = err
+		return typed
+	}
+
+	typed.Decoded = &decoded
+	return typed
+}
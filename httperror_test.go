@@ -0,0 +1,90 @@
+package roku
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type (
+	upstreamErr struct {
+		Error struct {
+			Code   string   `json:"code"`
+			Fields []string `json:"fields"`
+		} `json:"error"`
+	}
+)
+
+func TestFetchingWithTypedErrorDecodesErrorBody(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", MediaTypeJSON)
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = fmt.Fprint(w, `{"error": {"code": "E42", "fields": ["name", "email"]}, "trace": "t-1"}`)
+	})
+	defer ts.Close()
+
+	ch := FetchRxE[NoReq, getUserEnvV1Res, upstreamErr](
+		context.Background(),
+		httpClient,
+		Get,
+		ts.URL,
+		nil,
+		nil,
+		DeadLine,
+		time.Millisecond,
+		MaxRetries,
+	).Observe()
+
+	_, err := To[Envelope[getUserEnvV1Res]](<-ch)
+
+	var errTyped HTTPError[upstreamErr]
+	if !errors.As(err, &errTyped) {
+		t.Fatalf("wrong error: %v", err)
+	}
+
+	if errTyped.Decoded == nil {
+		t.Fatalf("Expected a decoded body, Got: %v", errTyped.DecodeErr)
+	}
+
+	want := []string{"name", "email"}
+	if errTyped.Decoded.Error.Code != "E42" || !cmp.Equal(want, errTyped.Decoded.Error.Fields) {
+		t.Errorf("Expected E42 on %v, Got: %+v", want, *errTyped.Decoded)
+	}
+
+	var errHTTP ErrInvalidHTTPStatus
+	if !errors.As(err, &errHTTP) || errHTTP.Res.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("Expected the status error to be reachable, Got: %v", err)
+	}
+}
+
+func TestSendingWithTypedErrorFallsBackToRawBody(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = fmt.Fprint(w, "upstream down")
+	})
+	defer ts.Close()
+
+	client := NewClient(WithHTTPClient(httpClient), WithRetries(time.Millisecond, 0))
+
+	_, err := SendE[NoReq, getUserEnvV1Res, upstreamErr](context.Background(), client, Get, ts.URL, nil)
+
+	var errTyped HTTPError[upstreamErr]
+	if !errors.As(err, &errTyped) {
+		t.Fatalf("wrong error: %v", err)
+	}
+
+	if errTyped.Decoded != nil || errTyped.DecodeErr == nil {
+		t.Errorf("Expected a decoding error, Got: %+v", errTyped)
+	}
+
+	if string(errTyped.Body) != "upstream down" {
+		t.Errorf("Expected the raw body, Got: %q", errTyped.Body)
+	}
+}
@@ -0,0 +1,55 @@
+package roku
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+)
+
+// isIdempotent reports whether sending a request with method more than once
+// has the same effect as sending it once.
+func isIdempotent(method HTTPMethod) bool {
+	switch method {
+	case Get, Head, Put, Delete, Options:
+		return true
+	default:
+		return false
+	}
+}
+
+// forMethod returns the settings used to retry a call with method. Calls with
+// non-idempotent methods are sent only once, unless idempotency keys are
+// enabled: then every attempt of the call carries the same Idempotency-Key.
+// A key already set in the headers is kept as it is.
+func (s settings) forMethod(method HTTPMethod) (settings, error) {
+	if isIdempotent(method) {
+		return s, nil
+	}
+
+	if !s.idempotencyKeys {
+		return s.with(WithRetries(s.retryPolicy.InitialInterval, 0)), nil
+	}
+
+	key, err := newIdempotencyKey()
+	if err != nil {
+		return settings{}, err
+	}
+
+	return s.with(WithHeaders(withDefaultHeader(s.headers, IdempotencyKeyHeader, key))), nil
+}
+
+// newIdempotencyKey returns a random version 4 UUID.
+func newIdempotencyKey() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("idempotency key: %w", err)
+	}
+
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
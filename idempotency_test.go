@@ -0,0 +1,121 @@
+package roku
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFetchingWithFailingServerRetriesOnlyIdempotentCalls(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		method HTTPMethod
+		opts   []Option
+		want   int
+	}{
+		"with get":                    {method: Get, want: 4},
+		"with put":                    {method: Put, want: 4},
+		"with delete":                 {method: Delete, want: 4},
+		"with post":                   {method: Post, want: 1},
+		"with patch":                  {method: Patch, want: 1},
+		"with post and keys":          {method: Post, opts: []Option{WithIdempotencyKeys(true)}, want: 4},
+		"with patch and keys":         {method: Patch, opts: []Option{WithIdempotencyKeys(true)}, want: 4},
+		"with post and keys disabled": {method: Post, opts: []Option{WithIdempotencyKeys(false)}, want: 1},
+	}
+
+	for input, tc := range cases {
+		tc := tc
+		t.Run(input, func(t *testing.T) {
+			t.Parallel()
+
+			var mu sync.Mutex
+			var keys []string
+			ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+				mu.Unlock()
+				errorResponse(w, r, http.StatusServiceUnavailable, http.StatusText(http.StatusServiceUnavailable))
+			})
+			defer ts.Close()
+
+			client := NewClient(WithHTTPClient(httpClient), WithRetries(time.Millisecond, 3))
+
+			ch := SendRx[createUserV1Req, getUserEnvV1Res](
+				context.Background(),
+				client,
+				tc.method,
+				ts.URL,
+				&createUserV1Req{Name: "Marco"},
+				tc.opts...,
+			).Observe()
+
+			_, err := To[Envelope[getUserEnvV1Res]](<-ch)
+
+			var errHTTP ErrInvalidHTTPStatus
+			if !errors.As(err, &errHTTP) {
+				t.Fatalf("wrong error: %v", err)
+			}
+
+			if len(keys) != tc.want {
+				t.Fatalf("Expected %d attempts, Got: %d", tc.want, len(keys))
+			}
+
+			for _, key := range keys {
+				if key != keys[0] {
+					t.Errorf("Expected the same key on every attempt, Got: %v", keys)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchingWithIdempotencyKeysGeneratesOneKeyPerCall(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var keys []string
+	ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+		mu.Unlock()
+		getUserHandler(w, r)
+	})
+	defer ts.Close()
+
+	client := NewClient(WithHTTPClient(httpClient), WithBaseURL(ts.URL), WithIdempotencyKeys(true))
+
+	for i := 0; i < 2; i++ {
+		ch := SendRx[createUserV1Req, getUserEnvV1Res](context.Background(), client, Post, "/users", &createUserV1Req{}).Observe()
+		if _, err := To[Envelope[getUserEnvV1Res]](<-ch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ch := SendRx[createUserV1Req, getUserEnvV1Res](
+		context.Background(),
+		client,
+		Post,
+		"/users",
+		&createUserV1Req{},
+		WithHeaders(map[string]string{"idempotency-key": "order-42"}),
+	).Observe()
+	if _, err := To[Envelope[getUserEnvV1Res]](<-ch); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(keys) != 3 {
+		t.Fatalf("Expected 3 calls, Got: %v", keys)
+	}
+
+	if len(keys[0]) != 36 || keys[0] == keys[1] {
+		t.Errorf("Expected distinct generated keys, Got: %v", keys[:2])
+	}
+
+	if keys[2] != "order-42" {
+		t.Errorf("Expected the caller key to be kept, Got: %q", keys[2])
+	}
+}
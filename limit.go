@@ -0,0 +1,35 @@
+package roku
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// limitBody makes reads of res.Body fail with *http.MaxBytesError past limit
+// bytes. A limit lower than one leaves the body unbounded.
+func limitBody(res *http.Response, limit int64) {
+	if res == nil || res.Body == nil || limit < 1 {
+		return
+	}
+	res.Body = http.MaxBytesReader(nil, res.Body, limit)
+}
+
+// readBody reads body to the end, mapping an exceeded limit to
+// ErrBodySizeLimit.
+func readBody(body io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, bodyErr(err)
+	}
+	return data, nil
+}
+
+func bodyErr(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return fmt.Errorf("%w. Max size is %d bytes", ErrBodySizeLimit, maxBytesErr.Limit)
+	}
+	return err
+}
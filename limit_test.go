@@ -0,0 +1,103 @@
+package roku
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSendingWithMaxResponseSizeLimitsBody(t *testing.T) {
+	t.Parallel()
+	ts := getUserSvr()
+	defer ts.Close()
+
+	client := NewClient(WithHTTPClient(httpClient), WithBaseURL(ts.URL), WithMaxResponseSize(16))
+
+	cases := map[string]struct {
+		opts []Option
+		want error
+	}{
+		"with client limit": {
+			opts: nil,
+			want: ErrBodySizeLimit,
+		},
+		"with call limit": {
+			opts: []Option{WithMaxResponseSize(1 << 20)},
+			want: nil,
+		},
+		"with disabled limit": {
+			opts: []Option{WithMaxResponseSize(0)},
+			want: nil,
+		},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			_, err := Send[NoReq, getUserEnvV1Res](context.Background(), client, Get, "", nil, tc.opts...)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("wrong error: %v", err)
+			}
+
+			if tc.want != nil && !strings.Contains(err.Error(), "Max size is 16 bytes") {
+				t.Errorf("Expected the limit in the error, Got: %v", err)
+			}
+		})
+	}
+}
+
+func TestSendingWithMaxResponseSizeLimitsErrorBody(t *testing.T) {
+	t.Parallel()
+	ts := notFoundResSvr()
+	defer ts.Close()
+
+	client := NewClient(WithHTTPClient(httpClient), WithMaxResponseSize(8))
+
+	_, err := Send[NoReq, getUserEnvV1Res](context.Background(), client, Get, ts.URL, nil)
+
+	var errHTTP ErrInvalidHTTPStatus
+	if !errors.As(err, &errHTTP) {
+		t.Fatalf("wrong error: %v", err)
+	}
+
+	errDesc := GetErrorDesc(errHTTP)
+	if errDesc.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status code: %d, Got: %d", http.StatusNotFound, errDesc.StatusCode)
+	}
+
+	if want := string(errHTTP.Body); len(want) != 8 || errDesc.ErrMessage != want {
+		t.Errorf("Expected the first 8 bytes of the body, Got: %q", errDesc.ErrMessage)
+	}
+
+	if !errors.Is(errHTTP.BodyErr, ErrBodySizeLimit) {
+		t.Errorf("Expected: %v, Got: %v", ErrBodySizeLimit, errHTTP.BodyErr)
+	}
+}
+
+func TestSendingWithLargeMaxResponseSizeCapsErrorBody(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write(bytes.Repeat([]byte("x"), 2*MaxErrorBody))
+	})
+	defer ts.Close()
+
+	client := NewClient(WithHTTPClient(httpClient), WithMaxResponseSize(4*MaxErrorBody))
+
+	_, err := Send[NoReq, getUserEnvV1Res](context.Background(), client, Get, ts.URL, nil)
+
+	var errHTTP ErrInvalidHTTPStatus
+	if !errors.As(err, &errHTTP) {
+		t.Fatalf("wrong error: %v", err)
+	}
+
+	if len(errHTTP.Body) != MaxErrorBody {
+		t.Errorf("Expected %d bytes, Got: %d", MaxErrorBody, len(errHTTP.Body))
+	}
+
+	if !errors.Is(errHTTP.BodyErr, ErrBodySizeLimit) {
+		t.Errorf("Expected: %v, Got: %v", ErrBodySizeLimit, errHTTP.BodyErr)
+	}
+}
@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	ErrBulkheadFull = rejection("bulkhead is full")
+)
+
+type (
+	// rejection is an error of a request rejected before being sent, which
+	// retrying right away would not fix.
+	rejection string
+
+	// Bulkhead is a RoundTripper that limits the requests in flight for each
+	// key, so that a slow downstream cannot hold every goroutine and
+	// connection. A request stays in flight until its response body is read to
+	// the end or closed.
+	Bulkhead struct {
+		*bulkhead
+		next http.RoundTripper
+	}
+
+	bulkhead struct {
+		maxConcurrent int
+		maxQueue      int
+		queueTimeout  time.Duration
+		key           KeyFunc
+		mu            sync.Mutex
+		compartments  map[string]*compartment
+	}
+
+	compartment struct {
+		slots   chan struct{}
+		waiting int
+	}
+
+	releaseBody struct {
+		io.ReadCloser
+		once    sync.Once
+		release func()
+	}
+)
+
+func (r rejection) Error() string {
+	return string(r)
+}
+
+// Retryable reports that the request should not be retried, so that retries
+// do not add to the load of a saturated downstream.
+func (r rejection) Retryable() bool {
+	return false
+}
+
+// NewBulkhead allows maxConcurrent requests in flight for each key returned by
+// key. Up to maxQueue more requests wait for a slot, for at most queueTimeout
+// or until their context is done; the others fail with ErrBulkheadFull. A
+// queueTimeout of zero makes them wait only on their context. Copies made by
+// Wrap share the slots.
+func NewBulkhead(maxConcurrent int, maxQueue int, queueTimeout time.Duration, key KeyFunc) (Bulkhead, error) {
+	switch {
+	case maxConcurrent < 1:
+		return Bulkhead{}, fmt.Errorf("bulkhead: max concurrent requests %d is lower than 1", maxConcurrent)
+	case maxQueue < 0:
+		return Bulkhead{}, fmt.Errorf("bulkhead: negative max queue %d", maxQueue)
+	case queueTimeout < 0:
+		return Bulkhead{}, fmt.Errorf("bulkhead: negative queue timeout %v", queueTimeout)
+	case key == nil:
+		return Bulkhead{}, errors.New("bulkhead: nil key function")
+	}
+
+	b := Bulkhead{
+		bulkhead: &bulkhead{
+			maxConcurrent: maxConcurrent,
+			maxQueue:      maxQueue,
+			queueTimeout:  queueTimeout,
+			key:           key,
+			compartments:  make(map[string]*compartment),
+		},
+	}
+	return b, nil
+}
+
+// Named puts every request in the same bulkhead, called name.
+func Named(name string) KeyFunc {
+	return func(*http.Request) string {
+		return name
+	}
+}
+
+func (b Bulkhead) Wrap(next http.RoundTripper) http.RoundTripper {
+	b.next = next
+	return b
+}
+
+func (b Bulkhead) RoundTrip(r *http.Request) (*http.Response, error) {
+	key := b.key(r)
+	if key == "" {
+		return nextOrDefault(b.next).RoundTrip(r)
+	}
+
+	release, err := b.acquire(r, key)
+	if err != nil {
+		closeBody(r)
+		return nil, err
+	}
+
+	res, err := nextOrDefault(b.next).RoundTrip(r)
+	if err != nil || res.Body == nil || res.Body == http.NoBody {
+		release()
+		return res, err
+	}
+
+	res.Body = &releaseBody{ReadCloser: res.Body, release: release}
+	return res, nil
+}
+
+// acquire takes a slot of the compartment of key, waiting in its queue when
+// they are all taken, and returns the function that gives it back.
+func (b *bulkhead) acquire(r *http.Request, key string) (func(), error) {
+	b.mu.Lock()
+	c, ok := b.compartments[key]
+	if !ok {
+		c = &compartment{slots: make(chan struct{}, b.maxConcurrent)}
+		b.compartments[key] = c
+	}
+
+	select {
+	case c.slots <- struct{}{}:
+		b.mu.Unlock()
+		return c.release, nil
+	default:
+	}
+
+	if c.waiting >= b.maxQueue {
+		b.mu.Unlock()
+		return nil, fmt.Errorf("%w for %q", ErrBulkheadFull, key)
+	}
+	c.waiting++
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		c.waiting--
+		b.mu.Unlock()
+	}()
+
+	var timeout <-chan time.Time
+	if b.queueTimeout > 0 {
+		timer := time.NewTimer(b.queueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case c.slots <- struct{}{}:
+		return c.release, nil
+	case <-timeout:
+		return nil, fmt.Errorf("%w for %q: no slot within %v", ErrBulkheadFull, key, b.queueTimeout)
+	case <-r.Context().Done():
+		return nil, r.Context().Err()
+	}
+}
+
+func (c *compartment) release() {
+	<-c.slots
+}
+
+func (b *releaseBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if errors.Is(err, io.EOF) {
+		b.once.Do(b.release)
+	}
+	return n, err
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildingBulkheadValidatesIt(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		maxConcurrent int
+		maxQueue      int
+		queueTimeout  time.Duration
+		key           KeyFunc
+		wantErr       bool
+	}{
+		"with valid settings":  {maxConcurrent: 1, key: ByHost},
+		"with zero concurrent": {maxConcurrent: 0, key: ByHost, wantErr: true},
+		"with negative queue":  {maxConcurrent: 1, maxQueue: -1, key: ByHost, wantErr: true},
+		"with negative wait":   {maxConcurrent: 1, queueTimeout: -time.Second, key: ByHost, wantErr: true},
+		"with nil key":         {maxConcurrent: 1, wantErr: true},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			_, err := NewBulkhead(tc.maxConcurrent, tc.maxQueue, tc.queueTimeout, tc.key)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error: %t, Got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSendingThroughFullBulkheadRejectsRequests(t *testing.T) {
+	t.Parallel()
+
+	b, err := NewBulkhead(1, 1, 20*time.Millisecond, ByHost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := b.Wrap(okTransport("user"))
+
+	res, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "http://example.com", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queued := make(chan error, 1)
+	go func() {
+		_, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "http://example.com", nil))
+		queued <- err
+	}()
+	time.Sleep(5 * time.Millisecond)
+
+	_, err = transport.RoundTrip(httptest.NewRequest(http.MethodGet, "http://example.com", nil))
+	if !errors.Is(err, ErrBulkheadFull) {
+		t.Errorf("Expected: %v, Got: %v", ErrBulkheadFull, err)
+	}
+
+	var retryable interface{ Retryable() bool }
+	if !errors.As(err, &retryable) || retryable.Retryable() {
+		t.Errorf("Expected a rejection that is not retryable, Got: %v", err)
+	}
+
+	if err := <-queued; !errors.Is(err, ErrBulkheadFull) {
+		t.Errorf("Expected the queued request to time out with %v, Got: %v", ErrBulkheadFull, err)
+	}
+
+	if _, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "http://other.com", nil)); err != nil {
+		t.Errorf("Expected another key to have its own slots, Got: %v", err)
+	}
+
+	_ = res.Body.Close()
+}
+
+func TestSendingThroughBulkheadStopsQueuingWithContext(t *testing.T) {
+	t.Parallel()
+
+	b, err := NewBulkhead(1, 1, 0, Named("users"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := b.Wrap(okTransport("user"))
+
+	if _, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "http://example.com", nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	r := httptest.NewRequest(http.MethodGet, "http://example.com", nil).WithContext(ctx)
+	if _, err := transport.RoundTrip(r); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected: %v, Got: %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestSendingThroughBulkheadReleasesSlots(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		next    http.RoundTripper
+		consume func(res *http.Response)
+	}{
+		"with body closed": {
+			next: okTransport("user"),
+			consume: func(res *http.Response) {
+				_ = res.Body.Close()
+			},
+		},
+		"with body read to the end": {
+			next: okTransport("user"),
+			consume: func(res *http.Response) {
+				_, _ = io.ReadAll(res.Body)
+			},
+		},
+		"with no content": {
+			next: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody, Request: r}, nil
+			}),
+			consume: func(*http.Response) {},
+		},
+		"with failed request": {
+			next: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+				return nil, errors.New("connection refused")
+			}),
+			consume: func(*http.Response) {},
+		},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			b, err := NewBulkhead(1, 0, 0, ByHost)
+			if err != nil {
+				t.Fatal(err)
+			}
+			transport := b.Wrap(tc.next)
+
+			for i := 0; i < 3; i++ {
+				res, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "http://example.com", nil))
+				if errors.Is(err, ErrBulkheadFull) {
+					t.Fatalf("Expected request %d to get the released slot, Got: %v", i+1, err)
+				}
+				if err == nil {
+					tc.consume(res)
+				}
+			}
+		})
+	}
+}
+
+func okTransport(body string) http.RoundTripper {
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		res := &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    r,
+		}
+		return res, nil
+	})
+}
@@ -4,6 +4,7 @@ import "net/http"
 
 type CustomHeaders struct {
 	headers map[string]string
+	next    http.RoundTripper
 }
 
 func NewCustomHeaders(headers map[string]string) CustomHeaders {
@@ -13,10 +14,24 @@ func NewCustomHeaders(headers map[string]string) CustomHeaders {
 	return cHeaders
 }
 
+func (c CustomHeaders) Wrap(next http.RoundTripper) http.RoundTripper {
+	c.next = next
+	return c
+}
+
 func (c CustomHeaders) RoundTrip(r *http.Request) (*http.Response, error) {
 	reqCopy := r.Clone(r.Context())
 	for k, v := range c.headers {
 		reqCopy.Header.Add(k, v)
 	}
-	return http.DefaultTransport.RoundTrip(reqCopy)
+	return nextOrDefault(c.next).RoundTrip(reqCopy)
+}
+
+// nextOrDefault returns the transport set by Wrap, or http.DefaultTransport
+// when the middleware is used on its own.
+func nextOrDefault(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		return http.DefaultTransport
+	}
+	return next
 }
@@ -6,15 +6,37 @@ import (
 )
 
 type LoggingTransport struct {
-	log *log.Logger
+	log  *log.Logger
+	next http.RoundTripper
+}
+
+func NewLoggingTransport(logger *log.Logger) LoggingTransport {
+	transport := LoggingTransport{
+		log: logger,
+	}
+	return transport
+}
+
+func (l LoggingTransport) Wrap(next http.RoundTripper) http.RoundTripper {
+	l.next = next
+	return l
 }
 
 func (l LoggingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
-	l.log.Printf(
+	logger := l.log
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	logger.Printf(
 		"Sending a %s request to %s over %s\n",
 		r.Method, r.URL, r.Proto,
 	)
-	resp, err := http.DefaultTransport.RoundTrip(r)
-	l.log.Printf("Got back a response over %s\n", resp.Proto)
+	resp, err := nextOrDefault(l.next).RoundTrip(r)
+	if err != nil {
+		logger.Printf("Got back an error: %v\n", err)
+		return resp, err
+	}
+	logger.Printf("Got back a response over %s\n", resp.Proto)
 	return resp, err
 }
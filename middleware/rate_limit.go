@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+)
+
+const (
+	// Wait makes RateLimiter hold a request until a token is available or
+	// its context is done.
+	Wait LimitMode = iota
+	// FailFast makes RateLimiter reject a request with ErrRateLimited when
+	// no token is available.
+	FailFast
+)
+
+var (
+	ErrRateLimited = rejection("rate limit exceeded")
+)
+
+type (
+	LimitMode int
+
+	// KeyFunc returns the key whose bucket limits r. Requests with an empty
+	// key are not limited.
+	KeyFunc func(r *http.Request) string
+
+	// RateLimiter is a RoundTripper that limits the requests sent through it
+	// with a token bucket per key.
+	RateLimiter struct {
+		*limiter
+		next http.RoundTripper
+	}
+
+	limiter struct {
+		rate    float64
+		burst   int
+		key     KeyFunc
+		mode    LimitMode
+		mu      sync.Mutex
+		buckets map[string]*bucket
+	}
+
+	bucket struct {
+		tokens float64
+		last   time.Time
+	}
+)
+
+// NewRateLimiter allows rate requests per second for each key returned by
+// key, with bursts of up to burst requests. Copies made by Wrap share the
+// buckets.
+func NewRateLimiter(rate float64, burst int, key KeyFunc, mode LimitMode) (RateLimiter, error) {
+	switch {
+	case rate <= 0:
+		return RateLimiter{}, fmt.Errorf("rate limiter: rate %v is not positive", rate)
+	case burst < 1:
+		return RateLimiter{}, fmt.Errorf("rate limiter: burst %d is lower than 1", burst)
+	case key == nil:
+		return RateLimiter{}, errors.New("rate limiter: nil key function")
+	}
+
+	rl := RateLimiter{
+		limiter: &limiter{
+			rate:    rate,
+			burst:   burst,
+			key:     key,
+			mode:    mode,
+			buckets: make(map[string]*bucket),
+		},
+	}
+	return rl, nil
+}
+
+// ByHost limits the requests of every host separately.
+func ByHost(r *http.Request) string {
+	return r.URL.Host
+}
+
+// ByPath limits the requests whose path matches one of patterns, with the
+// syntax of path.Match, separately for every pattern. Other requests are not
+// limited.
+func ByPath(patterns ...string) KeyFunc {
+	return func(r *http.Request) string {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, r.URL.Path); ok {
+				return r.URL.Host + pattern
+			}
+		}
+		return ""
+	}
+}
+
+// ByHeader limits the requests of every value of the header name separately,
+// e.g. of every API key. Requests without it are not limited.
+func ByHeader(name string) KeyFunc {
+	return func(r *http.Request) string {
+		return r.Header.Get(name)
+	}
+}
+
+func (rl RateLimiter) Wrap(next http.RoundTripper) http.RoundTripper {
+	rl.next = next
+	return rl
+}
+
+func (rl RateLimiter) RoundTrip(r *http.Request) (*http.Response, error) {
+	key := rl.key(r)
+	if key == "" {
+		return nextOrDefault(rl.next).RoundTrip(r)
+	}
+
+	delay, ok := rl.reserve(key, time.Now())
+	if !ok {
+		closeBody(r)
+		return nil, fmt.Errorf("%w for %q", ErrRateLimited, key)
+	}
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-r.Context().Done():
+			timer.Stop()
+			rl.cancel(key)
+			closeBody(r)
+			return nil, r.Context().Err()
+		case <-timer.C:
+		}
+	}
+
+	return nextOrDefault(rl.next).RoundTrip(r)
+}
+
+// reserve takes a token from the bucket of key and returns how long to wait
+// before it is available. In FailFast mode, it reports false instead of
+// waiting.
+func (l *limiter) reserve(key string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.burst), last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = min(float64(l.burst), b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+
+	if l.mode == FailFast {
+		return 0, false
+	}
+
+	b.tokens--
+	return time.Duration(-b.tokens / l.rate * float64(time.Second)), true
+}
+
+// cancel gives back the token reserved by a request that stopped waiting.
+func (l *limiter) cancel(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.buckets[key].tokens++
+}
+
+// closeBody closes the body of a request that is not sent, as RoundTrip must.
+func closeBody(r *http.Request) {
+	if r.Body != nil {
+		_ = r.Body.Close()
+	}
+}
@@ -0,0 +1,216 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestBuildingRateLimiterValidatesIt(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		rate    float64
+		burst   int
+		key     KeyFunc
+		wantErr bool
+	}{
+		"with valid settings": {rate: 1, burst: 1, key: ByHost},
+		"with zero rate":      {rate: 0, burst: 1, key: ByHost, wantErr: true},
+		"with zero burst":     {rate: 1, burst: 0, key: ByHost, wantErr: true},
+		"with nil key":        {rate: 1, burst: 1, wantErr: true},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			_, err := NewRateLimiter(tc.rate, tc.burst, tc.key, Wait)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error: %t, Got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestReservingTokensRefillsBuckets(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		mode      LimitMode
+		wantDelay time.Duration
+		wantOk    bool
+	}{
+		"with wait":      {mode: Wait, wantDelay: 100 * time.Millisecond, wantOk: true},
+		"with fail fast": {mode: FailFast, wantDelay: 0, wantOk: false},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			rl, err := NewRateLimiter(10, 2, ByHost, tc.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			now := time.Now()
+			for i := 0; i < 2; i++ {
+				if delay, ok := rl.reserve("a", now); !ok || delay != 0 {
+					t.Fatalf("Expected token %d of the burst, Got: %v %t", i+1, delay, ok)
+				}
+			}
+
+			if delay, ok := rl.reserve("a", now); ok != tc.wantOk || delay != tc.wantDelay {
+				t.Errorf("Expected: %v %t, Got: %v %t", tc.wantDelay, tc.wantOk, delay, ok)
+			}
+
+			if delay, ok := rl.reserve("b", now); !ok || delay != 0 {
+				t.Errorf("Expected another key to have its own bucket, Got: %v %t", delay, ok)
+			}
+
+			later := now.Add(time.Second)
+			if delay, ok := rl.reserve("a", later); !ok || delay != 0 {
+				t.Errorf("Expected the bucket to be refilled, Got: %v %t", delay, ok)
+			}
+		})
+	}
+}
+
+func TestCancelingReservationRefundsToken(t *testing.T) {
+	t.Parallel()
+
+	rl, err := NewRateLimiter(10, 1, ByHost, Wait)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	rl.reserve("a", now)
+
+	delay, _ := rl.reserve("a", now)
+	if delay != 100*time.Millisecond {
+		t.Fatalf("Expected: %v, Got: %v", 100*time.Millisecond, delay)
+	}
+	rl.cancel("a")
+
+	if delay, _ := rl.reserve("a", now); delay != 100*time.Millisecond {
+		t.Errorf("Expected the canceled token to be given back, Got: %v", delay)
+	}
+}
+
+func TestSendingThroughRateLimiterLimitsRequests(t *testing.T) {
+	t.Parallel()
+
+	var sent int
+	next := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		sent++
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: r}, nil
+	})
+
+	rl, err := NewRateLimiter(0.001, 1, ByHeader("X-Api-Key"), FailFast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := rl.Wrap(next)
+
+	send := func(key string) error {
+		r := httptest.NewRequest(http.MethodGet, "http://example.com/users", nil)
+		if key != "" {
+			r.Header.Set("X-Api-Key", key)
+		}
+		_, err := transport.RoundTrip(r)
+		return err
+	}
+
+	if err := send("k1"); err != nil {
+		t.Fatal(err)
+	}
+
+	err = send("k1")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Expected: %v, Got: %v", ErrRateLimited, err)
+	}
+
+	var retryable interface{ Retryable() bool }
+	if !errors.As(err, &retryable) || retryable.Retryable() {
+		t.Errorf("Expected a rejection that is not retryable, Got: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := send(""); err != nil {
+			t.Errorf("Expected requests without key to be unlimited, Got: %v", err)
+		}
+	}
+
+	if sent != 4 {
+		t.Errorf("Expected 4 requests, Got: %d", sent)
+	}
+}
+
+func TestSendingThroughRateLimiterStopsWaitingWithContext(t *testing.T) {
+	t.Parallel()
+
+	next := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: r}, nil
+	})
+
+	rl, err := NewRateLimiter(0.001, 1, ByHost, Wait)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := rl.Wrap(next)
+
+	if _, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "http://example.com", nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	r := httptest.NewRequest(http.MethodGet, "http://example.com", nil).WithContext(ctx)
+	if _, err := transport.RoundTrip(r); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected: %v, Got: %v", context.DeadlineExceeded, err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the wait to stop with the context, Got: %v", elapsed)
+	}
+
+	if tokens := rl.buckets["example.com"].tokens; tokens < -0.01 {
+		t.Errorf("Expected the token to be given back, Got: %v tokens", tokens)
+	}
+}
+
+func TestKeyingRequests(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodGet, "http://example.com/users/42", nil)
+	r.Header.Set("X-Api-Key", "k1")
+
+	cases := map[string]struct {
+		key  KeyFunc
+		want string
+	}{
+		"by host":           {key: ByHost, want: "example.com"},
+		"by matching path":  {key: ByPath("/orders/*", "/users/*"), want: "example.com/users/*"},
+		"by other path":     {key: ByPath("/orders/*"), want: ""},
+		"by header":         {key: ByHeader("X-Api-Key"), want: "k1"},
+		"by missing header": {key: ByHeader("X-Tenant"), want: ""},
+		"by name":           {key: Named("payments"), want: "payments"},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			if got := tc.key(r); got != tc.want {
+				t.Errorf("Expected: %q, Got: %q", tc.want, got)
+			}
+		})
+	}
+}
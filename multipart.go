@@ -0,0 +1,130 @@
+package roku
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+)
+
+type (
+	// ReqMultipartI is implemented by requests sent as a multipart/form-data
+	// body. GetParts is called once per attempt, so return fresh readers to
+	// keep uploads retryable.
+	ReqMultipartI interface {
+		GetParts() ([]Part, error)
+	}
+
+	Part struct {
+		Name        string
+		FileName    string
+		ContentType string
+		Content     io.Reader
+	}
+)
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func FieldPart(name string, value string) Part {
+	part := Part{
+		Name:    name,
+		Content: strings.NewReader(value),
+	}
+	return part
+}
+
+func FilePart(name string, fileName string, contentType string, content io.Reader) Part {
+	part := Part{
+		Name:        name,
+		FileName:    fileName,
+		ContentType: contentType,
+		Content:     content,
+	}
+	return part
+}
+
+func JSONPart(name string, value any) (Part, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return Part{}, fmt.Errorf("%q: %w", err.Error(), ErrMarshallValue)
+	}
+
+	part := Part{
+		Name:        name,
+		ContentType: "application/json",
+		Content:     bytes.NewReader(data),
+	}
+	return part, nil
+}
+
+// multipartReader streams parts through a pipe, so the payload is never held
+// in memory. The writing goroutine stops when the transport closes the body.
+func multipartReader(parts []Part) (io.Reader, string) {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+
+	go func() {
+		err := writeParts(mw, parts)
+		if err == nil {
+			err = mw.Close()
+		}
+		_ = pw.CloseWithError(err)
+	}()
+
+	return pr, mw.FormDataContentType()
+}
+
+// writeParts writes parts in order and closes the content of every part that
+// is an io.Closer, including those left unwritten when a write fails.
+func writeParts(mw *multipart.Writer, parts []Part) error {
+	for i, part := range parts {
+		if err := writePart(mw, part); err != nil {
+			for _, rest := range parts[i+1:] {
+				closeContent(rest)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writePart(mw *multipart.Writer, part Part) error {
+	defer closeContent(part)
+
+	header := make(textproto.MIMEHeader)
+
+	disposition := fmt.Sprintf(`form-data; name="%s"`, quoteEscaper.Replace(part.Name))
+	if part.FileName != "" {
+		disposition += fmt.Sprintf(`; filename="%s"`, quoteEscaper.Replace(part.FileName))
+	}
+	header.Set("Content-Disposition", disposition)
+
+	switch {
+	case part.ContentType != "":
+		header.Set("Content-Type", part.ContentType)
+	case part.FileName != "":
+		header.Set("Content-Type", "application/octet-stream")
+	}
+
+	w, err := mw.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	if part.Content == nil {
+		return nil
+	}
+
+	_, err = io.Copy(w, part.Content)
+	return err
+}
+
+func closeContent(part Part) {
+	if closer, ok := part.Content.(io.Closer); ok {
+		_ = closer.Close()
+	}
+}
@@ -0,0 +1,168 @@
+package roku
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+type (
+	uploadAvatarV1Req struct {
+		UserID  string
+		Avatar  string
+		Profile createUserV1Req
+	}
+
+	uploadPartRes struct {
+		FileName    string `json:"file_name,omitempty"`
+		ContentType string `json:"content_type,omitempty"`
+		Content     string `json:"content,omitempty"`
+	}
+
+	uploadV1Res struct {
+		ContentType string                   `json:"content_type,omitempty"`
+		Parts       map[string]uploadPartRes `json:"parts,omitempty"`
+	}
+
+	trackedContent struct {
+		io.Reader
+		closed bool
+	}
+)
+
+func (uploadAvatarV1Req) Req() {}
+
+func (u uploadAvatarV1Req) GetParts() ([]Part, error) {
+	profile, err := JSONPart("profile", u.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := []Part{
+		FieldPart("user_id", u.UserID),
+		FilePart("avatar", "avatar \"1\".png", "image/png", strings.NewReader(u.Avatar)),
+		profile,
+	}
+	return parts, nil
+}
+
+func (uploadV1Res) Res() {}
+
+func (c *trackedContent) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestSendingWithMultipartRequestStreamsParts(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(uploadHandler)
+	defer ts.Close()
+
+	client := NewClient(WithHTTPClient(httpClient), WithBaseURL(ts.URL))
+	req := uploadAvatarV1Req{UserID: "42", Avatar: "PNG-bytes", Profile: cuReq}
+
+	got, err := Send[uploadAvatarV1Req, uploadV1Res](context.Background(), client, Post, "/avatars", &req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(got.Body.ContentType, "multipart/form-data; boundary=") {
+		t.Errorf("Expected multipart content type, Got: %s", got.Body.ContentType)
+	}
+
+	want := map[string]uploadPartRes{
+		"user_id": {
+			Content: "42",
+		},
+		"avatar": {
+			FileName:    "avatar \"1\".png",
+			ContentType: "image/png",
+			Content:     "PNG-bytes",
+		},
+		"profile": {
+			ContentType: "application/json",
+			Content:     `{"name":"Adam Smith","email":"adam.smith@hotmail.com"}`,
+		},
+	}
+
+	if !(cmp.Equal(want, got.Body.Parts)) {
+		t.Error(cmp.Diff(want, got.Body.Parts))
+	}
+}
+
+func TestWritingPartsWithFailingContentClosesEveryPart(t *testing.T) {
+	t.Parallel()
+
+	errRead := errors.New("read failed")
+	contents := []*trackedContent{
+		{Reader: strings.NewReader("42")},
+		{Reader: iotest.ErrReader(errRead)},
+		{Reader: strings.NewReader("PNG-bytes")},
+		{Reader: strings.NewReader("{}")},
+	}
+
+	parts := make([]Part, 0, len(contents))
+	for i, content := range contents {
+		parts = append(parts, FilePart(fmt.Sprintf("file%d", i), "file.bin", "", content))
+	}
+
+	err := writeParts(multipart.NewWriter(io.Discard), parts)
+	if !errors.Is(err, errRead) {
+		t.Fatalf("Expected: %v, Got: %v", errRead, err)
+	}
+
+	for i, content := range contents {
+		if !content.closed {
+			t.Errorf("Expected part %d to be closed", i)
+		}
+	}
+}
+
+func uploadHandler(w http.ResponseWriter, r *http.Request) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		errorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	parts := map[string]uploadPartRes{}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			errorResponse(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		content, err := io.ReadAll(part)
+		if err != nil {
+			errorResponse(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		parts[part.FormName()] = uploadPartRes{
+			FileName:    part.FileName(),
+			ContentType: part.Header.Get("Content-Type"),
+			Content:     string(content),
+		}
+	}
+
+	env := envelope{
+		"content_type": r.Header.Get("Content-Type"),
+		"parts":        parts,
+	}
+
+	err = write(w, http.StatusOK, env, nil)
+	if err != nil {
+		serverErrorResponse(w, r)
+	}
+}
@@ -0,0 +1,118 @@
+package roku
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/reactivex/rxgo/v2"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	MediaTypeNDJSON = "application/x-ndjson"
+)
+
+type (
+	// ErrInvalidLine reports an NDJSON record that could not be decoded. The
+	// stream goes on with the next record.
+	ErrInvalidLine struct {
+		Line int
+		Err  error
+	}
+)
+
+func (e ErrInvalidLine) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e ErrInvalidLine) Unwrap() error {
+	return e.Err
+}
+
+// FetchNDJSONRx decodes a newline-delimited JSON response one record at a time
+// and emits each of them as a *I. Records that fail to decode are emitted as
+// ErrInvalidLine errors. Canceling ctx stops the stream, which the Timeout of
+// client does not cut.
+func FetchNDJSONRx[T ReqI, I any](
+	ctx context.Context,
+	client *http.Client,
+	method HTTPMethod,
+	endpoint string,
+	request *T,
+	headers map[string]string,
+	deadline time.Duration,
+) rxgo.Observable {
+	s := newSettings(
+		WithHTTPClient(client),
+		WithHeaders(headers),
+		WithDeadline(deadline),
+	)
+	return fetchNDJSONRx[T, I](ctx, &s, method, endpoint, request)
+}
+
+func SendNDJSONRx[T ReqI, I any](
+	ctx context.Context,
+	client *Client,
+	method HTTPMethod,
+	endpoint string,
+	request *T,
+	opts ...Option,
+) rxgo.Observable {
+	s := client.settings.with(opts...)
+	return fetchNDJSONRx[T, I](ctx, &s, method, endpoint, request)
+}
+
+func fetchNDJSONRx[T ReqI, I any](
+	ctx context.Context,
+	s *settings,
+	method HTTPMethod,
+	endpoint string,
+	request *T,
+) rxgo.Observable {
+	ndjson := s.streaming().with(WithHeaders(withDefaultHeader(s.headers, "Accept", MediaTypeNDJSON)))
+
+	return rxgo.Defer([]rxgo.Producer{
+		func(_ context.Context, next chan<- rxgo.Item) {
+			res, _, err := roundTrip[T](ctx, &ndjson, method, endpoint, request)
+			if err != nil {
+				rxgo.Error(err).SendContext(ctx, next)
+				return
+			}
+			defer res.Body.Close()
+
+			reader := bufio.NewReader(res.Body)
+
+			for line := 1; ; line++ {
+				record, err := reader.ReadBytes('\n')
+				if err != nil && !errors.Is(err, io.EOF) {
+					rxgo.Error(ErrInvalidLine{Line: line, Err: bodyErr(err)}).SendContext(ctx, next)
+					return
+				}
+				eof := err != nil
+
+				record = bytes.TrimSpace(record)
+				if len(record) > 0 {
+					var item I
+
+					decoded := rxgo.Of(&item)
+					decodeErr := readJSON(bytes.NewReader(record), &item, ndjson.strictness == Strict)
+					if decodeErr != nil {
+						decoded = rxgo.Error(ErrInvalidLine{Line: line, Err: decodeErr})
+					}
+
+					if !decoded.SendContext(ctx, next) {
+						return
+					}
+				}
+
+				if eof {
+					return
+				}
+			}
+		},
+	})
+}
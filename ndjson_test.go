@@ -0,0 +1,137 @@
+package roku
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestStreamingNDJSONResponseEmitsRecordsAndLineErrors(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(logExportHandler)
+	defer ts.Close()
+
+	client := NewClient(WithHTTPClient(httpClient), WithBaseURL(ts.URL))
+
+	ch := SendNDJSONRx[NoReq, userResV](context.Background(), client, Get, "/logs", nil).Observe()
+
+	var ids []string
+	var lineErrs []ErrInvalidLine
+
+	for item := range ch {
+		got, err := To[userResV](item)
+		if err != nil {
+			var errLine ErrInvalidLine
+			if !errors.As(err, &errLine) {
+				t.Fatalf("wrong error: %v", err)
+			}
+			lineErrs = append(lineErrs, errLine)
+			continue
+		}
+		ids = append(ids, got.ID)
+	}
+
+	wantIDs := []string{"a", "b", "d"}
+	if fmt.Sprint(ids) != fmt.Sprint(wantIDs) {
+		t.Errorf("Expected ids: %v, Got: %v", wantIDs, ids)
+	}
+
+	if len(lineErrs) != 2 {
+		t.Fatalf("Expected 2 line errors, Got: %v", lineErrs)
+	}
+
+	if lineErrs[0].Line != 3 || !errors.Is(lineErrs[0], ErrBadlyJSON) {
+		t.Errorf("Expected a badly-formed JSON error on line 3, Got: %v", lineErrs[0])
+	}
+
+	if lineErrs[1].Line != 5 || !errors.Is(lineErrs[1], ErrBodyUnknownKey) {
+		t.Errorf("Expected an unknown key error on line 5, Got: %v", lineErrs[1])
+	}
+}
+
+func TestStreamingNDJSONWithCanceledContextStopsEmitting(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(endlessLogHandler)
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := FetchNDJSONRx[NoReq, userResV](ctx, httpClient, Get, ts.URL, nil, nil, time.Second).Observe()
+
+	for i := 0; i < 5; i++ {
+		_, err := To[userResV](<-ch)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		for range ch {
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream did not stop after the context was canceled")
+	}
+}
+
+func TestStreamingNDJSONOutlivesClientTimeout(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(slowLogHandler)
+	defer ts.Close()
+
+	client := &http.Client{Timeout: 50 * time.Millisecond}
+	ch := FetchNDJSONRx[NoReq, userResV](context.Background(), client, Get, ts.URL, nil, nil, time.Second).Observe()
+
+	var ids []string
+	for item := range ch {
+		got, err := To[userResV](item)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, got.ID)
+	}
+
+	if len(ids) != 3 {
+		t.Errorf("Expected 3 records, Got: %v", ids)
+	}
+}
+
+func logExportHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", MediaTypeNDJSON)
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprint(w, "{\"id\": \"a\"}\n{\"id\": \"b\"}\r\n{\"id\": \n\n{\"id\": \"c\", \"level\": 1}\n{\"id\": \"d\"}")
+}
+
+func endlessLogHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", MediaTypeNDJSON)
+	w.WriteHeader(http.StatusOK)
+
+	for i := 0; ; i++ {
+		select {
+		case <-r.Context().Done():
+			return
+		default:
+		}
+		_, _ = fmt.Fprintf(w, "{\"id\": \"%d\"}\n", i)
+		w.(http.Flusher).Flush()
+	}
+}
+
+func slowLogHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", MediaTypeNDJSON)
+	w.WriteHeader(http.StatusOK)
+
+	for i := 0; i < 3; i++ {
+		_, _ = fmt.Fprintf(w, "{\"id\": \"%d\"}\n", i)
+		w.(http.Flusher).Flush()
+		time.Sleep(40 * time.Millisecond)
+	}
+}
@@ -0,0 +1,347 @@
+package roku
+
+import (
+	"fmt"
+	"github.com/samber/lo"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+type (
+	// Option configures a Client when passed to NewClient, or overrides the
+	// client configuration for a single call when passed to Send or SendRx.
+	Option func(*settings)
+
+	Middleware func(http.RoundTripper) http.RoundTripper
+
+	settings struct {
+		client            *http.Client
+		baseURL           string
+		headers           map[string]string
+		pathParams        map[string]string
+		deadline          time.Duration
+		retryPolicy       RetryPolicy
+		retryPolicyErr    error
+		validator         func(res *http.Response) bool
+		middlewares       []Middleware
+		codecs            codecs
+		mediaType         string
+		strictness        Strictness
+		unknownFieldsHook UnknownFieldsHook
+		maxResponseSize   int64
+		streamPath        []string
+		retryClassifier   RetryClassifier
+		maxRetryAfter     time.Duration
+		totalDeadline     time.Duration
+		retryBudget       *RetryBudget
+		retryBudgetHook   RetryBudgetHook
+		circuitBreaker    *CircuitBreaker
+		hedger            *Hedger
+		idempotencyKeys   bool
+	}
+)
+
+func newSettings(opts ...Option) settings {
+	s := settings{
+		client:   NewHTTPClient(ConTimeOut, nil, http.DefaultTransport),
+		deadline: DeadLine,
+		retryPolicy: RetryPolicy{
+			Strategy:        ExponentialBackoff,
+			InitialInterval: RetryInterval,
+			MaxInterval:     MaxRetryInterval,
+			Multiplier:      RetryMultiplier,
+			Jitter:          RetryJitter,
+			MaxRetries:      MaxRetries,
+		},
+		validator:       defaultInvalidStatusCodeValidator,
+		codecs:          newCodecs(),
+		mediaType:       MediaTypeJSON,
+		retryClassifier: DefaultRetryClassifier,
+		maxRetryAfter:   MaxRetryAfter,
+	}
+	return s.with(opts...)
+}
+
+// with returns a copy of s with opts applied. Options must not mutate maps or
+// slices shared with s, so that per-call overrides never leak into the Client.
+func (s settings) with(opts ...Option) settings {
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s
+}
+
+func (s settings) httpClient() *http.Client {
+	if len(s.middlewares) == 0 {
+		return s.client
+	}
+
+	base := s.client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	for i := len(s.middlewares) - 1; i >= 0; i-- {
+		base = s.middlewares[i](base)
+	}
+
+	client := *s.client
+	client.Transport = base
+	return &client
+}
+
+// streaming returns a copy of s for calls whose response body stays open as
+// long as the stream lasts, without the overall Timeout of the client, which
+// would cut the stream. The deadline still bounds the wait for the response.
+func (s settings) streaming() settings {
+	if s.client == nil || s.client.Timeout == 0 {
+		return s
+	}
+
+	client := *s.client
+	client.Timeout = 0
+	s.client = &client
+	return s
+}
+
+func (s settings) resolve(endpoint string) (string, error) {
+	if s.baseURL == "" {
+		return endpoint, nil
+	}
+
+	ref, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("%q: %w", err.Error(), ErrBadEndpoint)
+	}
+
+	if ref.IsAbs() {
+		return endpoint, nil
+	}
+
+	if endpoint == "" {
+		return s.baseURL, nil
+	}
+
+	return strings.TrimRight(s.baseURL, "/") + "/" + strings.TrimLeft(endpoint, "/"), nil
+}
+
+// host returns the host that endpoint is sent to, or an empty string when it
+// cannot be resolved.
+func (s settings) host(endpoint string) string {
+	resolved, err := s.resolve(endpoint)
+	if err != nil {
+		return ""
+	}
+
+	u, err := url.Parse(resolved)
+	if err != nil {
+		return ""
+	}
+
+	return u.Host
+}
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *settings) {
+		s.client = client
+	}
+}
+
+// WithBaseURL sets the URL that relative endpoints are appended to.
+// Absolute endpoints are sent as they are.
+func WithBaseURL(baseURL string) Option {
+	return func(s *settings) {
+		s.baseURL = baseURL
+	}
+}
+
+// WithHeaders adds headers to every request, replacing previously configured
+// values for the same keys.
+func WithHeaders(headers map[string]string) Option {
+	return func(s *settings) {
+		if len(headers) == 0 {
+			return
+		}
+		s.headers = lo.Assign[string, string](s.headers, headers)
+	}
+}
+
+// WithPathParams sets the values of the {name} placeholders in the endpoint.
+// They take precedence over the values of `path` tags on the request type.
+func WithPathParams(params map[string]string) Option {
+	return func(s *settings) {
+		if len(params) == 0 {
+			return
+		}
+		s.pathParams = lo.Assign[string, string](s.pathParams, params)
+	}
+}
+
+func WithDeadline(deadline time.Duration) Option {
+	return func(s *settings) {
+		s.deadline = deadline
+	}
+}
+
+// WithRetries sets the initial interval and the number of retries of the
+// current retry policy.
+func WithRetries(backoffInterval time.Duration, backoffRetries uint64) Option {
+	return func(s *settings) {
+		s.retryPolicy.InitialInterval = backoffInterval
+		s.retryPolicy.MaxInterval = max(s.retryPolicy.MaxInterval, backoffInterval)
+		s.retryPolicy.MaxRetries = backoffRetries
+	}
+}
+
+// WithRetryPolicy replaces the retry policy, filling its zero fields with
+// NewRetryPolicy. The calls made with a policy that NewRetryPolicy rejects
+// fail with its error before any attempt.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(s *settings) {
+		validated, err := NewRetryPolicy(policy)
+		s.retryPolicyErr = err
+		if err != nil {
+			return
+		}
+		s.retryPolicy = validated
+	}
+}
+
+// WithTotalDeadline bounds the time FetchRx spends on a call, counting every
+// attempt and the delays between them, while WithDeadline bounds each attempt.
+// Zero, the default, leaves the call bounded only by its context.
+func WithTotalDeadline(deadline time.Duration) Option {
+	return func(s *settings) {
+		s.totalDeadline = deadline
+	}
+}
+
+// WithRetryBudget makes FetchRx retry only while budget allows it.
+func WithRetryBudget(budget *RetryBudget) Option {
+	return func(s *settings) {
+		s.retryBudget = budget
+	}
+}
+
+// WithRetryBudgetHook sets the function called when an attempt is not retried
+// because the retry budget was exhausted.
+func WithRetryBudgetHook(hook RetryBudgetHook) Option {
+	return func(s *settings) {
+		s.retryBudgetHook = hook
+	}
+}
+
+// WithCircuitBreaker makes calls go through breaker.
+func WithCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(s *settings) {
+		s.circuitBreaker = breaker
+	}
+}
+
+// WithHedging makes GET, HEAD, PUT, DELETE and OPTIONS calls go through
+// hedger.
+func WithHedging(hedger *Hedger) Option {
+	return func(s *settings) {
+		s.hedger = hedger
+	}
+}
+
+// WithRetryClassifier replaces DefaultRetryClassifier to choose which failed
+// attempts are retried.
+func WithRetryClassifier(classifier RetryClassifier) Option {
+	return func(s *settings) {
+		if classifier == nil {
+			return
+		}
+		s.retryClassifier = classifier
+	}
+}
+
+// WithMaxRetryAfter caps the delay requested by the Retry-After header of a
+// failed response. It defaults to MaxRetryAfter.
+func WithMaxRetryAfter(maxRetryAfter time.Duration) Option {
+	return func(s *settings) {
+		s.maxRetryAfter = maxRetryAfter
+	}
+}
+
+// WithIdempotencyKeys lets FetchRx retry POST and PATCH calls by sending an
+// Idempotency-Key header, generated once per call and reused by every attempt.
+// Without it, only GET, HEAD, PUT, DELETE and OPTIONS calls are retried.
+func WithIdempotencyKeys(enabled bool) Option {
+	return func(s *settings) {
+		s.idempotencyKeys = enabled
+	}
+}
+
+// WithStatusCodeValidator replaces the default validator, which rejects all
+// 4XX and 5XX responses. Calling it without a validator keeps the current one.
+func WithStatusCodeValidator(statusCodeValidator ...func(res *http.Response) bool) Option {
+	return func(s *settings) {
+		if len(statusCodeValidator) == 0 || statusCodeValidator[0] == nil {
+			return
+		}
+		s.validator = statusCodeValidator[0]
+	}
+}
+
+// WithMiddleware wraps the transport of the underlying *http.Client. The first
+// middleware is the outermost one.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(s *settings) {
+		s.middlewares = append(slices.Clip(s.middlewares), middlewares...)
+	}
+}
+
+// WithCodec registers codec for mediaTypes, or for its own media type when
+// none is given. Responses are decoded with the codec matching their
+// Content-Type.
+func WithCodec(codec Codec, mediaTypes ...string) Option {
+	return func(s *settings) {
+		s.codecs = s.codecs.with(codec, mediaTypes...)
+	}
+}
+
+// WithMediaType selects the registered codec used to encode request bodies
+// and the media type preferred in the Accept header. It defaults to JSON.
+func WithMediaType(mediaType string) Option {
+	return func(s *settings) {
+		s.mediaType = normalizeMediaType(mediaType)
+	}
+}
+
+// WithStrictness sets how response fields unknown to the response type are
+// handled. It defaults to Strict.
+func WithStrictness(strictness Strictness) Option {
+	return func(s *settings) {
+		s.strictness = strictness
+	}
+}
+
+// WithUnknownFieldsHook sets the function called with the unknown field paths
+// found while decoding with the Report strictness.
+func WithUnknownFieldsHook(hook UnknownFieldsHook) Option {
+	return func(s *settings) {
+		s.unknownFieldsHook = hook
+	}
+}
+
+// WithMaxResponseSize bounds the bytes read from response bodies, including
+// the error bodies captured by ErrInvalidHTTPStatus. Reads past it fail with
+// ErrBodySizeLimit. A size lower than one disables the limit, the default.
+func WithMaxResponseSize(size int64) Option {
+	return func(s *settings) {
+		s.maxResponseSize = size
+	}
+}
+
+// WithStreamPath sets the object keys leading to the array decoded by
+// SendStreamRx when it is nested inside an object.
+func WithStreamPath(jsonPath ...string) Option {
+	return func(s *settings) {
+		s.streamPath = slices.Clone(jsonPath)
+	}
+}
@@ -0,0 +1,150 @@
+package roku
+
+import (
+	"context"
+	"github.com/google/go-cmp/cmp"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSendingWithBaseURLReturnsNonEmptyRes(t *testing.T) {
+	t.Parallel()
+	ts := upsertUserSvr()
+	defer ts.Close()
+
+	client := NewClient(
+		WithHTTPClient(httpClient),
+		WithBaseURL(ts.URL+"/"),
+		WithDeadline(time.Second),
+		WithRetries(150*time.Millisecond, 3),
+	)
+
+	cases := map[string]struct {
+		httpMethod HTTPMethod
+		endpoint   string
+		request    createUserV1Req
+		want       *getUserEnvV1Res
+	}{
+		"with relative endpoint": {
+			httpMethod: Post,
+			endpoint:   "/users",
+			request:    cuReq,
+			want:       userEnvRes,
+		},
+		"with absolute endpoint": {
+			httpMethod: Put,
+			endpoint:   ts.URL + "/users",
+			request:    cuReq,
+			want:       userEnvRes,
+		},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			ch := SendRx[createUserV1Req, getUserEnvV1Res](
+				context.Background(),
+				client,
+				tc.httpMethod,
+				tc.endpoint,
+				&tc.request,
+			).Observe()
+
+			got, err := To[Envelope[getUserEnvV1Res]](<-ch)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !(cmp.Equal(tc.want, got.Body)) {
+				t.Error(cmp.Diff(tc.want, got.Body))
+			}
+		})
+	}
+}
+
+func TestSendingWithCallHeadersOverridesClientHeaders(t *testing.T) {
+	t.Parallel()
+	ts := headerEchoSvr()
+	defer ts.Close()
+
+	client := NewClient(
+		WithHTTPClient(httpClient),
+		WithBaseURL(ts.URL),
+		WithHeaders(authorizationHeader),
+	)
+
+	cases := map[string]struct {
+		opts []Option
+		want map[string]string
+	}{
+		"with client headers": {
+			opts: nil,
+			want: authorizationHeader,
+		},
+		"with call headers": {
+			opts: []Option{WithHeaders(linkHeader)},
+			want: headers,
+		},
+		"with overridden header": {
+			opts: []Option{WithHeaders(map[string]string{"Authorization": "Basic x"})},
+			want: map[string]string{"Authorization": "Basic x"},
+		},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			got, err := Send[NoReq, getUserEnvV1Res](
+				context.Background(),
+				client,
+				Get,
+				"",
+				nil,
+				tc.opts...,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for k, v := range tc.want {
+				if got.Header.Get(k) != v {
+					t.Fatalf("Expected header: %s:%s, Got: %s:%s", k, v, k, got.Header.Get(k))
+				}
+			}
+		})
+	}
+
+	if len(client.settings.headers) != len(authorizationHeader) {
+		t.Fatalf("call options leaked into the client: %v", client.settings.headers)
+	}
+}
+
+func TestSendingWithMiddlewareWrapsTransport(t *testing.T) {
+	t.Parallel()
+	ts := headerEchoSvr()
+	defer ts.Close()
+
+	traced := func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			r = r.Clone(r.Context())
+			r.Header.Set("X-Trace", "roku")
+			return next.RoundTrip(r)
+		})
+	}
+
+	client := NewClient(WithHTTPClient(httpClient), WithMiddleware(traced))
+
+	got, err := Send[NoReq, getUserEnvV1Res](context.Background(), client, Get, ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Header.Get("X-Trace") != "roku" {
+		t.Fatalf("Expected header: X-Trace:roku, Got: %q", got.Header.Get("X-Trace"))
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
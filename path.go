@@ -0,0 +1,66 @@
+package roku
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const pathTag = "path"
+
+var (
+	ErrMissingPathParam = rokuErr("missing path parameter")
+)
+
+// expandPath replaces the {name} placeholders in endpoint with the path-escaped
+// values declared through `path` struct tags on request. Values in params take
+// precedence over the tagged ones. It reports whether any placeholder was
+// replaced.
+func expandPath[T ReqI](endpoint string, request *T, params map[string]string) (string, bool, error) {
+	if !strings.Contains(endpoint, "{") {
+		return endpoint, false, nil
+	}
+
+	values, err := encodeValues(request, pathTag)
+	if err != nil {
+		return "", false, err
+	}
+
+	for k, v := range params {
+		values.Set(k, v)
+	}
+
+	var expanded strings.Builder
+	var replaced bool
+
+	rest := endpoint
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			break
+		}
+
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+
+		name := rest[start+1 : end]
+		vs, ok := values[name]
+		if !ok || len(vs) == 0 || vs[0] == "" {
+			return "", false, fmt.Errorf("%w %q in %q", ErrMissingPathParam, name, endpoint)
+		}
+		if len(vs) > 1 {
+			return "", false, fmt.Errorf("%w: path parameter %q has %d values", ErrEncodeValue, name, len(vs))
+		}
+
+		expanded.WriteString(rest[:start])
+		expanded.WriteString(url.PathEscape(vs[0]))
+		rest = rest[end+1:]
+		replaced = true
+	}
+	expanded.WriteString(rest)
+
+	return expanded.String(), replaced, nil
+}
@@ -0,0 +1,225 @@
+package roku
+
+import (
+	"context"
+	"errors"
+	"github.com/v8tix/roku/codec"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+type (
+	getTenantUserV1Req struct {
+		Tenant string `path:"tenant" json:"-"`
+		ID     int    `path:"id" json:"-"`
+	}
+
+	pathEchoRes struct {
+		Path string `json:"path,omitempty"`
+	}
+
+	activateUserV1Req struct {
+		ID int `path:"id" json:"-"`
+	}
+
+	renameUserV1Req struct {
+		ID   int    `path:"id" json:"-"`
+		Name string `json:"name"`
+	}
+
+	bodyEchoRes struct {
+		ContentType string `json:"content_type,omitempty"`
+		Length      int    `json:"length"`
+	}
+)
+
+func (getTenantUserV1Req) Req() {}
+
+func (pathEchoRes) Res() {}
+
+func (activateUserV1Req) Req() {}
+
+func (renameUserV1Req) Req() {}
+
+func (bodyEchoRes) Res() {}
+
+func TestSendingWithPathTemplateEscapesPathParams(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(pathEchoHandler)
+	defer ts.Close()
+
+	client := NewClient(WithHTTPClient(httpClient), WithBaseURL(ts.URL))
+
+	cases := map[string]struct {
+		request *getTenantUserV1Req
+		opts    []Option
+		want    string
+	}{
+		"with tagged params": {
+			request: &getTenantUserV1Req{Tenant: "acme", ID: 42},
+			want:    "/tenants/acme/users/42",
+		},
+		"with params needing escaping": {
+			request: &getTenantUserV1Req{Tenant: "a/b c", ID: 7},
+			want:    "/tenants/a%2Fb%20c/users/7",
+		},
+		"with explicit params": {
+			request: &getTenantUserV1Req{Tenant: "acme", ID: 42},
+			opts:    []Option{WithPathParams(map[string]string{"tenant": "globex"})},
+			want:    "/tenants/globex/users/42",
+		},
+		"with only explicit params": {
+			request: nil,
+			opts:    []Option{WithPathParams(map[string]string{"tenant": "initech", "id": "1"})},
+			want:    "/tenants/initech/users/1",
+		},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			got, err := Send[getTenantUserV1Req, pathEchoRes](
+				context.Background(),
+				client,
+				Get,
+				"/tenants/{tenant}/users/{id}",
+				tc.request,
+				tc.opts...,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got.Body.Path != tc.want {
+				t.Errorf("Expected path: %s, Got: %s", tc.want, got.Body.Path)
+			}
+		})
+	}
+}
+
+func TestSendingWithMissingPathParamReturnsErrorBeforeCalling(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		pathEchoHandler(w, r)
+	})
+	defer ts.Close()
+
+	client := NewClient(WithHTTPClient(httpClient), WithBaseURL(ts.URL))
+
+	cases := map[string]struct {
+		request *getTenantUserV1Req
+		want    error
+	}{
+		"with empty tagged param": {
+			request: &getTenantUserV1Req{ID: 42},
+			want:    ErrMissingPathParam,
+		},
+		"with nil request": {
+			request: nil,
+			want:    ErrMissingPathParam,
+		},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			_, err := Send[getTenantUserV1Req, pathEchoRes](
+				context.Background(),
+				client,
+				Get,
+				"/tenants/{tenant}/users/{id}",
+				tc.request,
+			)
+			if !errors.Is(err, tc.want) {
+				t.Errorf("wrong error: %v", err)
+			}
+		})
+	}
+
+	if calls.Load() != 0 {
+		t.Fatalf("Expected no calls, Got: %d", calls.Load())
+	}
+}
+
+func TestSendingWithOnlyPathParamsSendsNoBody(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(bodyEchoHandler)
+	defer ts.Close()
+
+	client := NewClient(
+		WithHTTPClient(httpClient),
+		WithBaseURL(ts.URL),
+		WithCodec(codec.MsgPack{}),
+	)
+
+	cases := map[string]struct {
+		send func(opts ...Option) (*Envelope[bodyEchoRes], error)
+		opts []Option
+		want bodyEchoRes
+	}{
+		"with json": {
+			send: func(opts ...Option) (*Envelope[bodyEchoRes], error) {
+				return Send[activateUserV1Req, bodyEchoRes](context.Background(), client, Post, "/users/{id}/activate", &activateUserV1Req{ID: 1}, opts...)
+			},
+			want: bodyEchoRes{},
+		},
+		"with msgpack": {
+			send: func(opts ...Option) (*Envelope[bodyEchoRes], error) {
+				return Send[activateUserV1Req, bodyEchoRes](context.Background(), client, Post, "/users/{id}/activate", &activateUserV1Req{ID: 1}, opts...)
+			},
+			opts: []Option{WithMediaType(codec.MediaTypeMsgPack)},
+			want: bodyEchoRes{},
+		},
+		"with body fields": {
+			send: func(opts ...Option) (*Envelope[bodyEchoRes], error) {
+				return Send[renameUserV1Req, bodyEchoRes](context.Background(), client, Patch, "/users/{id}", &renameUserV1Req{ID: 1, Name: "Adam"}, opts...)
+			},
+			want: bodyEchoRes{ContentType: MediaTypeJSON, Length: len(`{"name":"Adam"}`)},
+		},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			got, err := tc.send(tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if *got.Body != tc.want {
+				t.Errorf("Expected: %+v, Got: %+v", tc.want, got.Body)
+			}
+		})
+	}
+}
+
+func bodyEchoHandler(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		serverErrorResponse(w, r)
+		return
+	}
+
+	env := envelope{
+		"content_type": r.Header.Get("Content-Type"),
+		"length":       len(body),
+	}
+
+	err = write(w, http.StatusOK, env, nil)
+	if err != nil {
+		serverErrorResponse(w, r)
+	}
+}
+
+func pathEchoHandler(w http.ResponseWriter, r *http.Request) {
+	env := envelope{
+		"path": r.URL.EscapedPath(),
+	}
+
+	err := write(w, http.StatusOK, env, nil)
+	if err != nil {
+		serverErrorResponse(w, r)
+	}
+}
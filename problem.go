@@ -0,0 +1,138 @@
+package roku
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const (
+	MediaTypeProblemJSON = "application/problem+json"
+)
+
+type (
+	// ProblemDetails is an RFC 9457 problem details object. ErrInvalidHTTPStatus
+	// parses it from application/problem+json bodies, and errors.As finds it
+	// there.
+	ProblemDetails struct {
+		Type     string
+		Title    string
+		Status   int
+		Detail   string
+		Instance string
+		// Extensions holds the members that are not defined by RFC 9457.
+		Extensions map[string]any
+	}
+
+	problemMembers struct {
+		Type     string `json:"type,omitempty"`
+		Title    string `json:"title,omitempty"`
+		Status   int    `json:"status,omitempty"`
+		Detail   string `json:"detail,omitempty"`
+		Instance string `json:"instance,omitempty"`
+	}
+)
+
+func (p *ProblemDetails) Error() string {
+	switch {
+	case p.Detail != "" && p.Title != "":
+		return fmt.Sprintf("%s: %s", p.Title, p.Detail)
+	case p.Detail != "":
+		return p.Detail
+	case p.Title != "":
+		return p.Title
+	default:
+		return fmt.Sprintf("problem of type %q", p.typeOrDefault())
+	}
+}
+
+func (p *ProblemDetails) typeOrDefault() string {
+	if p.Type == "" {
+		return "about:blank"
+	}
+	return p.Type
+}
+
+func (p ProblemDetails) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+
+	for k, v := range map[string]string{"type": p.Type, "title": p.Title, "detail": p.Detail, "instance": p.Instance} {
+		if v != "" {
+			members[k] = v
+		}
+	}
+
+	if p.Status != 0 {
+		members["status"] = p.Status
+	}
+
+	return json.Marshal(members)
+}
+
+func (p *ProblemDetails) UnmarshalJSON(data []byte) error {
+	var members problemMembers
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+
+	var extensions map[string]any
+	if err := json.Unmarshal(data, &extensions); err != nil {
+		return err
+	}
+
+	for _, k := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(extensions, k)
+	}
+
+	if len(extensions) == 0 {
+		extensions = nil
+	}
+
+	*p = ProblemDetails{
+		Type:       members.Type,
+		Title:      members.Title,
+		Status:     members.Status,
+		Detail:     members.Detail,
+		Instance:   members.Instance,
+		Extensions: extensions,
+	}
+	return nil
+}
+
+// WriteProblem writes problem as an application/problem+json response, with
+// its Status as the status code, or 500 when it has none.
+func WriteProblem(w http.ResponseWriter, problem ProblemDetails) error {
+	js, err := json.Marshal(problem)
+	if err != nil {
+		return err
+	}
+
+	status := problem.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", MediaTypeProblemJSON)
+	w.WriteHeader(status)
+	_, err = w.Write(js)
+
+	return err
+}
+
+// parseProblem decodes the problem details of an application/problem+json
+// error body, or returns nil.
+func parseProblem(res *http.Response, body []byte) *ProblemDetails {
+	if normalizeMediaType(res.Header.Get("Content-Type")) != MediaTypeProblemJSON {
+		return nil
+	}
+
+	var problem ProblemDetails
+	if err := json.Unmarshal(body, &problem); err != nil {
+		return nil
+	}
+
+	return &problem
+}
@@ -0,0 +1,63 @@
+package roku
+
+import (
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"net/http"
+	"testing"
+)
+
+func TestFetchingWithProblemResponseParsesProblemDetails(t *testing.T) {
+	t.Parallel()
+
+	want := ProblemDetails{
+		Type:     "https://example.com/probs/out-of-credit",
+		Title:    "You do not have enough credit.",
+		Status:   http.StatusForbidden,
+		Detail:   "Your current balance is 30, but that costs 50.",
+		Instance: "/account/12345/msgs/abc",
+		Extensions: map[string]any{
+			"balance":  float64(30),
+			"accounts": []any{"/account/12345", "/account/67890"},
+		},
+	}
+
+	ts := newTestServer(func(w http.ResponseWriter, _ *http.Request) {
+		_ = WriteProblem(w, want)
+	})
+	defer ts.Close()
+
+	_, err := Fetch[NoReq, getUserEnvV1Res](context.Background(), httpClient, Get, ts.URL, nil, nil, DeadLine)
+
+	var problem *ProblemDetails
+	if !errors.As(err, &problem) {
+		t.Fatalf("wrong error: %v", err)
+	}
+
+	if !cmp.Equal(want, *problem) {
+		t.Error(cmp.Diff(want, *problem))
+	}
+
+	var errHTTP ErrInvalidHTTPStatus
+	if !errors.As(err, &errHTTP) || errHTTP.Res.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected the status error to be kept, Got: %v", err)
+	}
+
+	if got := problem.Error(); got != want.Title+": "+want.Detail {
+		t.Errorf("Expected: %q, Got: %q", want.Title+": "+want.Detail, got)
+	}
+}
+
+func TestFetchingWithOtherErrorResponseHasNoProblemDetails(t *testing.T) {
+	t.Parallel()
+	ts := notFoundResSvr()
+	defer ts.Close()
+
+	_, err := Fetch[NoReq, getUserEnvV1Res](context.Background(), httpClient, Get, ts.URL, nil, nil, DeadLine)
+
+	var problem *ProblemDetails
+	if errors.As(err, &problem) {
+		t.Errorf("Expected no problem details, Got: %v", problem)
+	}
+}
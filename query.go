@@ -0,0 +1,277 @@
+package roku
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const queryTag = "url"
+
+var (
+	ErrEncodeValue = rokuErr("value cannot be encoded as a URL parameter")
+
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+type (
+	// ReqQueryI is implemented by requests that send query-string parameters.
+	// The values are merged with the ones declared through `url` struct tags.
+	ReqQueryI interface {
+		GetQueryValues() url.Values
+	}
+
+	// ValueEncoder lets a field control its own URL parameter encoding. Every
+	// returned string is sent as a separate value for the field's key.
+	ValueEncoder interface {
+		EncodeValues() ([]string, error)
+	}
+)
+
+// withQuery appends the query parameters declared by request to endpoint,
+// keeping the parameters already present in it. It reports whether request
+// contributed any parameter.
+func withQuery[T ReqI](endpoint string, request *T) (string, bool, error) {
+	if request == nil {
+		return endpoint, false, nil
+	}
+
+	values, err := encodeValues(request, queryTag)
+	if err != nil {
+		return "", false, err
+	}
+
+	if reqQuery, ok := any(request).(ReqQueryI); ok {
+		for k, vs := range reqQuery.GetQueryValues() {
+			for _, v := range vs {
+				values.Add(k, v)
+			}
+		}
+	}
+
+	if len(values) == 0 {
+		return endpoint, false, nil
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", false, fmt.Errorf("%q: %w", err.Error(), ErrBadEndpoint)
+	}
+
+	query := u.Query()
+	for k, vs := range values {
+		for _, v := range vs {
+			query.Add(k, v)
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String(), true, nil
+}
+
+// encodeValues encodes the struct fields of value tagged with tag. Untagged
+// fields are ignored, except embedded structs whose fields are promoted.
+func encodeValues(value any, tag string) (url.Values, error) {
+	values := url.Values{}
+
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return values, nil
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return values, nil
+	}
+
+	err := encodeStruct(values, v, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// declaresTag reports whether t, or a struct embedded in it, has a field
+// tagged with tag.
+func declaresTag(t reflect.Type, tag string) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if _, ok := field.Tag.Lookup(tag); ok {
+			return true
+		}
+		if field.Anonymous && declaresTag(field.Type, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// declaresBody reports whether encoding/json would encode any field of a
+// value of type t, i.e. whether it is more than an empty object.
+func declaresBody(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct ||
+		reflect.PointerTo(t).Implements(jsonMarshalerType) ||
+		reflect.PointerTo(t).Implements(textMarshalerType) {
+		return true
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Tag.Get("json") == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		switch {
+		case field.Anonymous && fieldType.Kind() == reflect.Struct:
+			if declaresBody(fieldType) {
+				return true
+			}
+		case field.IsExported():
+			return true
+		}
+	}
+
+	return false
+}
+
+func encodeStruct(values url.Values, v reflect.Value, tag string) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldValue := v.Field(i)
+
+		raw, ok := field.Tag.Lookup(tag)
+		if !ok {
+			if field.Anonymous {
+				embedded := reflect.Indirect(fieldValue)
+				if embedded.Kind() == reflect.Struct {
+					if err := encodeStruct(values, embedded, tag); err != nil {
+						return err
+					}
+				}
+			}
+			continue
+		}
+
+		name, opts, _ := strings.Cut(raw, ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if strings.Contains(","+opts+",", ",omitempty,") && fieldValue.IsZero() {
+			continue
+		}
+
+		encoded, err := encodeValue(fieldValue)
+		if err != nil {
+			return fmt.Errorf("%w: field %q", err, field.Name)
+		}
+
+		for _, e := range encoded {
+			values.Add(name, e)
+		}
+	}
+
+	return nil
+}
+
+func encodeValue(v reflect.Value) ([]string, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+		if encoded, ok, err := encodeCustom(v); ok {
+			return encoded, err
+		}
+		v = v.Elem()
+	}
+
+	if encoded, ok, err := encodeCustom(v); ok {
+		return encoded, err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return []string{v.String()}, nil
+	case reflect.Bool:
+		return []string{strconv.FormatBool(v.Bool())}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return []string{strconv.FormatInt(v.Int(), 10)}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return []string{strconv.FormatUint(v.Uint(), 10)}, nil
+	case reflect.Float32:
+		return []string{strconv.FormatFloat(v.Float(), 'f', -1, 32)}, nil
+	case reflect.Float64:
+		return []string{strconv.FormatFloat(v.Float(), 'f', -1, 64)}, nil
+	case reflect.Slice, reflect.Array:
+		var encoded []string
+		for i := 0; i < v.Len(); i++ {
+			e, err := encodeValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			encoded = append(encoded, e...)
+		}
+		return encoded, nil
+	default:
+		return nil, ErrEncodeValue
+	}
+}
+
+func encodeCustom(v reflect.Value) ([]string, bool, error) {
+	if !v.CanInterface() {
+		return nil, false, nil
+	}
+
+	candidates := []reflect.Value{v}
+	if v.CanAddr() {
+		candidates = append(candidates, v.Addr())
+	}
+
+	for _, c := range candidates {
+		switch encoder := c.Interface().(type) {
+		case ValueEncoder:
+			encoded, err := encoder.EncodeValues()
+			return encoded, true, err
+		case encoding.TextMarshaler:
+			text, err := encoder.MarshalText()
+			if err != nil {
+				return nil, true, fmt.Errorf("%q: %w", err.Error(), ErrEncodeValue)
+			}
+			return []string{string(text)}, true, nil
+		}
+	}
+
+	return nil, false, nil
+}
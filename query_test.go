@@ -0,0 +1,190 @@
+package roku
+
+import (
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type (
+	sortOrder string
+
+	listUsersV1Req struct {
+		Page    int        `url:"page" json:"-"`
+		Limit   *int       `url:"limit,omitempty" json:"-"`
+		Tags    []string   `url:"tag,omitempty" json:"-"`
+		Since   time.Time  `url:"since,omitempty" json:"-"`
+		Order   sortOrder  `url:"order,omitempty" json:"-"`
+		Deleted *bool      `url:"deleted,omitempty" json:"-"`
+		Skipped string     `url:"-" json:"-"`
+		Ignored complex128 `json:"-"`
+	}
+
+	searchUsersV1Req struct {
+		createUserV1Req
+		Fields []string `url:"fields,omitempty" json:"-"`
+	}
+
+	badQueryV1Req struct {
+		Filter map[string]string `url:"filter" json:"-"`
+	}
+
+	queryEchoRes struct {
+		Query url.Values `json:"query,omitempty"`
+		Body  string     `json:"body,omitempty"`
+	}
+)
+
+func (listUsersV1Req) Req() {}
+
+func (r listUsersV1Req) GetQueryValues() url.Values {
+	return url.Values{"cursor": {"abc"}}
+}
+
+func (searchUsersV1Req) Req() {}
+
+func (badQueryV1Req) Req() {}
+
+func (queryEchoRes) Res() {}
+
+func (s sortOrder) EncodeValues() ([]string, error) {
+	return []string{strings.ToUpper(string(s))}, nil
+}
+
+func TestSendingWithQueryParamsMergesQueryIntoEndpoint(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(queryEchoHandler)
+	defer ts.Close()
+
+	client := NewClient(WithHTTPClient(httpClient), WithBaseURL(ts.URL))
+	limit := 50
+	deleted := false
+	since := time.Date(2024, 5, 6, 18, 54, 15, 0, time.UTC)
+
+	cases := map[string]struct {
+		endpoint  string
+		request   *listUsersV1Req
+		wantQuery url.Values
+	}{
+		"with scalar values": {
+			endpoint: "/users",
+			request:  &listUsersV1Req{Page: 2, Limit: &limit},
+			wantQuery: url.Values{
+				"page":   {"2"},
+				"limit":  {"50"},
+				"cursor": {"abc"},
+			},
+		},
+		"with slices, times, custom encoders and pointers": {
+			endpoint: "/users",
+			request: &listUsersV1Req{
+				Page:    1,
+				Tags:    []string{"a", "b c"},
+				Since:   since,
+				Order:   "asc",
+				Deleted: &deleted,
+				Skipped: "x",
+			},
+			wantQuery: url.Values{
+				"page":    {"1"},
+				"tag":     {"a", "b c"},
+				"since":   {"2024-05-06T18:54:15Z"},
+				"order":   {"ASC"},
+				"deleted": {"false"},
+				"cursor":  {"abc"},
+			},
+		},
+		"with existing query parameters": {
+			endpoint: "/users?sort=name&page=0",
+			request:  &listUsersV1Req{Page: 3},
+			wantQuery: url.Values{
+				"sort":   {"name"},
+				"page":   {"0", "3"},
+				"cursor": {"abc"},
+			},
+		},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			got, err := Send[listUsersV1Req, queryEchoRes](
+				context.Background(),
+				client,
+				Get,
+				tc.endpoint,
+				tc.request,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !(cmp.Equal(tc.wantQuery, got.Body.Query)) {
+				t.Error(cmp.Diff(tc.wantQuery, got.Body.Query))
+			}
+
+			if got.Body.Body != "" {
+				t.Errorf("query-only request sent a body: %q", got.Body.Body)
+			}
+		})
+	}
+}
+
+func TestSendingWithQueryParamsAndBodySendsBoth(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(queryEchoHandler)
+	defer ts.Close()
+
+	client := NewClient(WithHTTPClient(httpClient))
+	req := searchUsersV1Req{createUserV1Req: cuReq, Fields: []string{"id", "name"}}
+
+	got, err := Send[searchUsersV1Req, queryEchoRes](context.Background(), client, Post, ts.URL, &req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantQuery := url.Values{"fields": {"id", "name"}}
+	if !(cmp.Equal(wantQuery, got.Body.Query)) {
+		t.Error(cmp.Diff(wantQuery, got.Body.Query))
+	}
+
+	wantBody := `{"name":"Adam Smith","email":"adam.smith@hotmail.com"}`
+	if got.Body.Body != wantBody {
+		t.Errorf("Expected body: %s, Got: %s", wantBody, got.Body.Body)
+	}
+}
+
+func TestSendingWithUnsupportedQueryValueReturnsError(t *testing.T) {
+	t.Parallel()
+
+	client := NewClient(WithHTTPClient(httpClient))
+	req := badQueryV1Req{Filter: map[string]string{"a": "b"}}
+
+	_, err := Send[badQueryV1Req, queryEchoRes](context.Background(), client, Get, "http://localhost", &req)
+	if !errors.Is(err, ErrEncodeValue) {
+		t.Errorf("wrong error: %v", err)
+	}
+}
+
+func queryEchoHandler(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		serverErrorResponse(w, r)
+		return
+	}
+
+	env := envelope{
+		"query": r.URL.Query(),
+		"body":  string(body),
+	}
+
+	err = write(w, http.StatusOK, env, nil)
+	if err != nil {
+		serverErrorResponse(w, r)
+	}
+}
@@ -0,0 +1,139 @@
+package roku
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/v8tix/roku/middleware"
+	"log"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchingWithRateLimiterWaitsForTokens(t *testing.T) {
+	t.Parallel()
+
+	var keys atomic.Int32
+	ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") == "k1" {
+			keys.Add(1)
+		}
+		getUserHandler(w, r)
+	})
+	defer ts.Close()
+
+	limiter, err := middleware.NewRateLimiter(20, 1, middleware.ByHost, middleware.Wait)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var logs bytes.Buffer
+	client := NewClient(
+		WithHTTPClient(httpClient),
+		WithBaseURL(ts.URL),
+		WithMiddleware(
+			middleware.NewLoggingTransport(log.New(&logs, "", 0)).Wrap,
+			limiter.Wrap,
+			middleware.NewCustomHeaders(map[string]string{"X-Api-Key": "k1"}).Wrap,
+		),
+	)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := Send[NoReq, getUserEnvV1Res](context.Background(), client, Get, "/users", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Expected the calls to wait for tokens, Got: %v", elapsed)
+	}
+
+	if got := strings.Count(logs.String(), "Sending a GET request"); got != 3 {
+		t.Errorf("Expected 3 logged requests, Got: %d", got)
+	}
+
+	if keys.Load() != 3 {
+		t.Errorf("Expected 3 requests with the custom header, Got: %d", keys.Load())
+	}
+}
+
+func TestFetchingRxWithRateLimiterDoesNotRetryRejections(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		getUserHandler(w, r)
+	})
+	defer ts.Close()
+
+	limiter, err := middleware.NewRateLimiter(0.001, 1, middleware.ByHost, middleware.FailFast)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClient(
+		WithHTTPClient(httpClient),
+		WithBaseURL(ts.URL),
+		WithMiddleware(limiter.Wrap),
+		WithRetries(100*time.Millisecond, 3),
+	)
+
+	if _, err := To[Envelope[getUserEnvV1Res]](<-SendRx[NoReq, getUserEnvV1Res](context.Background(), client, Get, "/users", nil).Observe()); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	_, err = To[Envelope[getUserEnvV1Res]](<-SendRx[NoReq, getUserEnvV1Res](context.Background(), client, Get, "/users", nil).Observe())
+	if !errors.Is(err, middleware.ErrRateLimited) {
+		t.Fatalf("Expected: %v, Got: %v", middleware.ErrRateLimited, err)
+	}
+
+	if elapsed := time.Since(start); elapsed >= 100*time.Millisecond {
+		t.Errorf("Expected the rejection to be returned without retrying, Got: %v", elapsed)
+	}
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected 1 request, Got: %d", calls.Load())
+	}
+}
+
+func TestFetchingWithRateLimiterRespectsContext(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(getUserHandler)
+	defer ts.Close()
+
+	limiter, err := middleware.NewRateLimiter(0.001, 1, middleware.ByPath("/users/*"), middleware.Wait)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClient(WithHTTPClient(httpClient), WithBaseURL(ts.URL), WithMiddleware(limiter.Wrap))
+
+	for i := 0; i < 3; i++ {
+		if _, err := Send[NoReq, getUserEnvV1Res](context.Background(), client, Get, "/users", nil); err != nil {
+			t.Fatalf("Expected paths out of the patterns to be unlimited, Got: %v", err)
+		}
+	}
+
+	if _, err := Send[NoReq, getUserEnvV1Res](context.Background(), client, Get, "/users/1", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = Send[NoReq, getUserEnvV1Res](ctx, client, Get, "/users/2", nil)
+	if err == nil {
+		t.Fatal("Expected the call to fail once the context expired")
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the wait to stop with the context, Got: %v", elapsed)
+	}
+}
@@ -0,0 +1,185 @@
+package roku
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cenkalti/backoff/v4"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrTimeBudgetExhausted = rokuErr("time budget exhausted before the next attempt")
+)
+
+type (
+	// RetryClassifier reports whether a failed attempt is worth retrying.
+	RetryClassifier func(err error) bool
+
+	attemptKey struct{}
+)
+
+// DefaultRetryClassifier retries connection errors, timeouts, and the 408, 429,
+// 502, 503 and 504 status codes. Any other error, e.g. a 4XX status or a body
+// that cannot be decoded, is returned right away, as are errors whose
+// Retryable method, like that of middleware.ErrBulkheadFull, returns false.
+func DefaultRetryClassifier(err error) bool {
+	var errHTTP ErrInvalidHTTPStatus
+	if errors.As(err, &errHTTP) {
+		if errHTTP.Res == nil {
+			return false
+		}
+
+		switch errHTTP.Res.StatusCode {
+		case http.StatusRequestTimeout,
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout:
+			return true
+		default:
+			return false
+		}
+	}
+
+	if errors.Is(err, ErrTimeOut) {
+		return true
+	}
+
+	var retryable interface{ Retryable() bool }
+	if errors.As(err, &retryable) {
+		return retryable.Retryable()
+	}
+
+	// *url.Error wraps every error of http.Client.Do, so look at its cause.
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// retry runs attempt until it succeeds, fails with an error the classifier
+// rejects, or the retry policy stops. The delay before a retry is at least
+// the Retry-After of a 429 or 503 response, capped by the configured maximum.
+// When the total deadline or the deadline of ctx leaves no time for the next
+// attempt, the last error is returned wrapped in ErrTimeBudgetExhausted. When
+// the retry budget of host is exhausted, the last error is returned as it is.
+func (s settings) retry(ctx context.Context, host string, attempt func(ctx context.Context) error) error {
+	callCtx := ctx
+	if s.totalDeadline > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, s.totalDeadline)
+		defer cancel()
+	}
+
+	policy := s.retryPolicy.backOff()
+	s.retryBudget.deposit(host, time.Now())
+
+	for n := 1; ; n++ {
+		err := attempt(context.WithValue(callCtx, attemptKey{}, n))
+		if err == nil {
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return err
+		}
+
+		if callCtx.Err() != nil {
+			return fmt.Errorf("%w: %w", ErrTimeBudgetExhausted, err)
+		}
+
+		if !s.retryClassifier(err) {
+			return err
+		}
+
+		delay := policy.NextBackOff()
+		if delay == backoff.Stop {
+			return err
+		}
+
+		if retryAfter, ok := retryAfterDelay(err, time.Now()); ok {
+			delay = max(delay, min(retryAfter, s.maxRetryAfter))
+		}
+
+		if deadline, ok := callCtx.Deadline(); ok && time.Until(deadline) < delay {
+			return fmt.Errorf("%w: %w", ErrTimeBudgetExhausted, err)
+		}
+
+		if !s.retryBudget.withdraw(host, time.Now()) {
+			if s.retryBudgetHook != nil {
+				s.retryBudgetHook(host, err)
+			}
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-callCtx.Done():
+			timer.Stop()
+			if ctx.Err() != nil {
+				return err
+			}
+			return fmt.Errorf("%w: %w", ErrTimeBudgetExhausted, err)
+		case <-timer.C:
+		}
+	}
+}
+
+// attemptFrom returns the number of the attempt run with ctx, starting at 1.
+func attemptFrom(ctx context.Context) int {
+	if n, ok := ctx.Value(attemptKey{}).(int); ok {
+		return n
+	}
+	return 1
+}
+
+// retryAfterDelay parses the Retry-After header of the 429 or 503 response
+// held by err, either as delta-seconds or as an HTTP-date.
+func retryAfterDelay(err error, now time.Time) (time.Duration, bool) {
+	var errHTTP ErrInvalidHTTPStatus
+	if !errors.As(err, &errHTTP) || errHTTP.Res == nil {
+		return 0, false
+	}
+
+	switch errHTTP.Res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+	default:
+		return 0, false
+	}
+
+	value := strings.TrimSpace(errHTTP.Res.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseUint(value, 10, 32); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return max(date.Sub(now), 0), true
+}
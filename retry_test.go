@@ -0,0 +1,347 @@
+package roku
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"github.com/v8tix/roku/middleware"
+	"github.com/v8tix/roku/policy"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestFetchingWithFailingServerRetriesRetryableErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		status     int
+		classifier RetryClassifier
+		want       int32
+	}{
+		"with bad request": {
+			status: http.StatusBadRequest,
+			want:   1,
+		},
+		"with not found": {
+			status: http.StatusNotFound,
+			want:   1,
+		},
+		"with too many requests": {
+			status: http.StatusTooManyRequests,
+			want:   4,
+		},
+		"with service unavailable": {
+			status: http.StatusServiceUnavailable,
+			want:   4,
+		},
+		"with internal server error": {
+			status: http.StatusInternalServerError,
+			want:   1,
+		},
+		"with custom classifier": {
+			status: http.StatusInternalServerError,
+			classifier: func(err error) bool {
+				var errHTTP ErrInvalidHTTPStatus
+				return errors.As(err, &errHTTP) && errHTTP.Res.StatusCode >= 500
+			},
+			want: 4,
+		},
+	}
+
+	for input, tc := range cases {
+		tc := tc
+		t.Run(input, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+			ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				errorResponse(w, r, tc.status, http.StatusText(tc.status))
+			})
+			defer ts.Close()
+
+			client := NewClient(WithHTTPClient(httpClient), WithRetries(time.Millisecond, 3))
+
+			ch := SendRx[NoReq, getUserEnvV1Res](
+				context.Background(),
+				client,
+				Get,
+				ts.URL,
+				nil,
+				WithRetryClassifier(tc.classifier),
+			).Observe()
+
+			_, err := To[Envelope[getUserEnvV1Res]](<-ch)
+
+			var errHTTP ErrInvalidHTTPStatus
+			if !errors.As(err, &errHTTP) {
+				t.Fatalf("wrong error: %v", err)
+			}
+
+			if errHTTP.Res.StatusCode != tc.status {
+				t.Errorf("Expected status code: %d, Got: %d", tc.status, errHTTP.Res.StatusCode)
+			}
+
+			if calls.Load() != tc.want {
+				t.Errorf("Expected %d attempts, Got: %d", tc.want, calls.Load())
+			}
+		})
+	}
+}
+
+func TestFetchingWithRedirectLoopDoesNotRetry(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Redirect(w, r, "/again", http.StatusFound)
+	})
+	defer ts.Close()
+
+	client := NewClient(WithHTTPClient(httpClient), WithRetries(time.Millisecond, 3))
+
+	ch := SendRx[NoReq, getUserEnvV1Res](context.Background(), client, Get, ts.URL, nil).Observe()
+
+	_, err := To[Envelope[getUserEnvV1Res]](<-ch)
+	if !errors.Is(err, policy.ErrMoreThanOneRedirect) {
+		t.Fatalf("Expected: %v, Got: %v", policy.ErrMoreThanOneRedirect, err)
+	}
+
+	if calls.Load() != 2 {
+		t.Errorf("Expected the 2 requests of a single attempt, Got: %d", calls.Load())
+	}
+}
+
+func TestClassifyingErrorsWithDefaultClassifierRetriesTransientErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input error
+		want  bool
+	}{
+		"with timeout":            {input: ErrTimeOut, want: true},
+		"with network error":      {input: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, want: true},
+		"with unknown key":        {input: ErrBodyUnknownKey, want: false},
+		"with bad gateway":        {input: ErrInvalidHTTPStatus{Res: &http.Response{StatusCode: http.StatusBadGateway}}, want: true},
+		"with gateway timeout":    {input: ErrInvalidHTTPStatus{Res: &http.Response{StatusCode: http.StatusGatewayTimeout}}, want: true},
+		"with unauthorized":       {input: ErrInvalidHTTPStatus{Res: &http.Response{StatusCode: http.StatusUnauthorized}}, want: false},
+		"with bulkhead full":      {input: &url.Error{Op: "Get", URL: "http://a", Err: middleware.ErrBulkheadFull}, want: false},
+		"with wrapped dial error": {input: &url.Error{Op: "Get", URL: "http://a", Err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}}, want: true},
+		"with unexpected eof":     {input: &url.Error{Op: "Get", URL: "http://a", Err: io.ErrUnexpectedEOF}, want: true},
+		"with connection reset":   {input: &url.Error{Op: "Get", URL: "http://a", Err: syscall.ECONNRESET}, want: true},
+		"with client timeout":     {input: &url.Error{Op: "Get", URL: "http://a", Err: context.DeadlineExceeded}, want: true},
+		"with unknown authority":  {input: &url.Error{Op: "Get", URL: "https://a", Err: x509.UnknownAuthorityError{}}, want: false},
+		"with unsupported scheme": {input: &url.Error{Op: "Get", URL: "ftp://a", Err: errors.New("unsupported protocol scheme \"ftp\"")}, want: false},
+		"with too many redirects": {input: &url.Error{Op: "Get", URL: "http://a", Err: policy.ErrMoreThanOneRedirect}, want: false},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			if got := DefaultRetryClassifier(tc.input); got != tc.want {
+				t.Errorf("Expected: %t, Got: %t", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestFetchingWithRetryAfterWaitsBeforeRetrying(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		status   int
+		minDelay time.Duration
+		maxDelay time.Duration
+	}{
+		"with too many requests": {status: http.StatusTooManyRequests, minDelay: 200 * time.Millisecond, maxDelay: time.Second},
+		"with bad gateway":       {status: http.StatusBadGateway, minDelay: 0, maxDelay: 200 * time.Millisecond},
+	}
+
+	for input, tc := range cases {
+		tc := tc
+		t.Run(input, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+			var first time.Time
+			var elapsed time.Duration
+
+			ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+				if calls.Add(1) == 1 {
+					first = time.Now()
+					w.Header().Set("Retry-After", "1")
+					errorResponse(w, r, tc.status, http.StatusText(tc.status))
+					return
+				}
+				elapsed = time.Since(first)
+				getUserHandler(w, r)
+			})
+			defer ts.Close()
+
+			client := NewClient(WithHTTPClient(httpClient), WithRetries(time.Millisecond, 3))
+
+			ch := SendRx[NoReq, getUserEnvV1Res](
+				context.Background(),
+				client,
+				Get,
+				ts.URL,
+				nil,
+				WithMaxRetryAfter(200*time.Millisecond),
+			).Observe()
+
+			_, err := To[Envelope[getUserEnvV1Res]](<-ch)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if calls.Load() != 2 {
+				t.Errorf("Expected 2 attempts, Got: %d", calls.Load())
+			}
+
+			if elapsed < tc.minDelay || elapsed >= tc.maxDelay {
+				t.Errorf("Expected a delay between %v and %v, Got: %v", tc.minDelay, tc.maxDelay, elapsed)
+			}
+		})
+	}
+}
+
+func TestFetchingWithRetryAfterBeyondDeadlineGivesUp(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "30")
+		errorResponse(w, r, http.StatusServiceUnavailable, http.StatusText(http.StatusServiceUnavailable))
+	})
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	client := NewClient(WithHTTPClient(httpClient), WithRetries(time.Millisecond, 3))
+
+	start := time.Now()
+	ch := SendRx[NoReq, getUserEnvV1Res](ctx, client, Get, ts.URL, nil).Observe()
+
+	_, err := To[Envelope[getUserEnvV1Res]](<-ch)
+
+	var errHTTP ErrInvalidHTTPStatus
+	if !errors.As(err, &errHTTP) || errHTTP.Res.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("wrong error: %v", err)
+	}
+
+	if !errors.Is(err, ErrTimeBudgetExhausted) {
+		t.Errorf("Expected: %v, Got: %v", ErrTimeBudgetExhausted, err)
+	}
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected 1 attempt, Got: %d", calls.Load())
+	}
+
+	if time.Since(start) > time.Second {
+		t.Errorf("Expected to give up right away, Got: %v", time.Since(start))
+	}
+}
+
+func TestFetchingWithTotalDeadlineStopsRetrying(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		interval time.Duration
+		minCalls int32
+		maxCalls int32
+	}{
+		"with delays within the deadline":  {interval: 50 * time.Millisecond, minCalls: 2, maxCalls: 6},
+		"with a delay beyond the deadline": {interval: time.Second, minCalls: 1, maxCalls: 1},
+	}
+
+	for input, tc := range cases {
+		tc := tc
+		t.Run(input, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+			ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				errorResponse(w, r, http.StatusServiceUnavailable, http.StatusText(http.StatusServiceUnavailable))
+			})
+			defer ts.Close()
+
+			policy, err := NewRetryPolicy(RetryPolicy{Strategy: ConstantBackoff, InitialInterval: tc.interval, MaxRetries: 100})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := NewClient(WithHTTPClient(httpClient), WithRetryPolicy(policy), WithTotalDeadline(275*time.Millisecond))
+
+			start := time.Now()
+			ch := SendRx[NoReq, getUserEnvV1Res](context.Background(), client, Get, ts.URL, nil).Observe()
+
+			_, err = To[Envelope[getUserEnvV1Res]](<-ch)
+			elapsed := time.Since(start)
+
+			if !errors.Is(err, ErrTimeBudgetExhausted) {
+				t.Fatalf("Expected: %v, Got: %v", ErrTimeBudgetExhausted, err)
+			}
+
+			var errHTTP ErrInvalidHTTPStatus
+			if !errors.As(err, &errHTTP) {
+				t.Errorf("Expected the last response error, Got: %v", err)
+			}
+
+			if elapsed > 275*time.Millisecond {
+				t.Errorf("Expected to stop within the deadline, Got: %v", elapsed)
+			}
+
+			if got := calls.Load(); got < tc.minCalls || got > tc.maxCalls {
+				t.Errorf("Expected between %d and %d attempts, Got: %d", tc.minCalls, tc.maxCalls, got)
+			}
+		})
+	}
+}
+
+func TestParsingRetryAfterHeader(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		input  string
+		status int
+		want   time.Duration
+		wantOk bool
+	}{
+		"with delta seconds": {input: "120", want: 2 * time.Minute, wantOk: true},
+		"with unavailable":   {input: "120", status: http.StatusServiceUnavailable, want: 2 * time.Minute, wantOk: true},
+		"with bad gateway":   {input: "120", status: http.StatusBadGateway, wantOk: false},
+		"with http date":     {input: now.Add(90 * time.Second).Format(http.TimeFormat), want: 90 * time.Second, wantOk: true},
+		"with past date":     {input: now.Add(-time.Minute).Format(http.TimeFormat), want: 0, wantOk: true},
+		"with negative":      {input: "-1", wantOk: false},
+		"with garbage":       {input: "soon", wantOk: false},
+		"with no header":     {input: "", wantOk: false},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			res := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+			if tc.status != 0 {
+				res.StatusCode = tc.status
+			}
+			if tc.input != "" {
+				res.Header.Set("Retry-After", tc.input)
+			}
+
+			got, ok := retryAfterDelay(ErrInvalidHTTPStatus{Res: res}, now)
+			if ok != tc.wantOk || got != tc.want {
+				t.Errorf("Expected: %v %t, Got: %v %t", tc.want, tc.wantOk, got, ok)
+			}
+		})
+	}
+}
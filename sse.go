@@ -0,0 +1,254 @@
+package roku
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/reactivex/rxgo/v2"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	MediaTypeEventStream = "text/event-stream"
+)
+
+var (
+	errStreamDone = rokuErr("event stream closed by the server")
+)
+
+type (
+	// Event is a server-sent event whose data was decoded into a D. String
+	// data is kept as it is, other types are decoded from JSON.
+	Event[D any] struct {
+		ID    string
+		Event string
+		Data  *D
+		Retry time.Duration
+	}
+
+	sseStream struct {
+		lastEventID string
+		retry       time.Duration
+	}
+)
+
+// SSERx consumes a text/event-stream response and emits every event as an
+// *Event[D]. When the connection drops or the server closes it, SSERx
+// reconnects with the Last-Event-ID header, waiting either the retry time
+// sent by the server or the next interval of the retry policy. It gives up
+// on errors rejected by the retry classifier or after backoffRetries
+// consecutive reconnections without events, and stops when ctx is canceled or
+// the server answers 204 No Content. The Timeout of client does not apply.
+func SSERx[T ReqI, D any](
+	ctx context.Context,
+	client *http.Client,
+	method HTTPMethod,
+	endpoint string,
+	request *T,
+	headers map[string]string,
+	deadline time.Duration,
+	backoffInterval time.Duration,
+	backoffRetries uint64,
+) rxgo.Observable {
+	s := newSettings(
+		WithHTTPClient(client),
+		WithHeaders(headers),
+		WithDeadline(deadline),
+		WithRetries(backoffInterval, backoffRetries),
+	)
+	return fetchSSERx[T, D](ctx, &s, method, endpoint, request)
+}
+
+func SendSSERx[T ReqI, D any](
+	ctx context.Context,
+	client *Client,
+	method HTTPMethod,
+	endpoint string,
+	request *T,
+	opts ...Option,
+) rxgo.Observable {
+	s := client.settings.with(opts...)
+	return fetchSSERx[T, D](ctx, &s, method, endpoint, request)
+}
+
+func fetchSSERx[T ReqI, D any](
+	ctx context.Context,
+	s *settings,
+	method HTTPMethod,
+	endpoint string,
+	request *T,
+) rxgo.Observable {
+	sse := s.streaming()
+
+	return rxgo.Defer([]rxgo.Producer{
+		func(_ context.Context, next chan<- rxgo.Item) {
+			if sse.retryPolicyErr != nil {
+				rxgo.Error(sse.retryPolicyErr).SendContext(ctx, next)
+				return
+			}
+
+			reconnects := sse.retryPolicy.backOff()
+
+			var stream sseStream
+
+			for {
+				received, err := readEvents[T, D](ctx, &sse, method, endpoint, request, &stream, next)
+				if ctx.Err() != nil || errors.Is(err, errStreamDone) {
+					return
+				}
+
+				if err != nil && !sse.retryClassifier(err) {
+					rxgo.Error(err).SendContext(ctx, next)
+					return
+				}
+
+				if received {
+					reconnects.Reset()
+				}
+
+				delay := reconnects.NextBackOff()
+				if delay == backoff.Stop {
+					if err != nil {
+						rxgo.Error(err).SendContext(ctx, next)
+					}
+					return
+				}
+
+				if stream.retry > 0 {
+					delay = stream.retry
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(delay):
+				}
+			}
+		},
+	})
+}
+
+// readEvents opens one connection and emits its events until it ends. It
+// reports whether any event was emitted.
+func readEvents[T ReqI, D any](
+	ctx context.Context,
+	s *settings,
+	method HTTPMethod,
+	endpoint string,
+	request *T,
+	stream *sseStream,
+	next chan<- rxgo.Item,
+) (bool, error) {
+	headers := map[string]string{
+		"Accept":        MediaTypeEventStream,
+		"Cache-Control": "no-cache",
+	}
+	if stream.lastEventID != "" {
+		headers["Last-Event-ID"] = stream.lastEventID
+	}
+	sse := s.with(WithHeaders(headers))
+
+	res, _, err := roundTrip[T](ctx, &sse, method, endpoint, request)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNoContent {
+		return false, errStreamDone
+	}
+
+	var (
+		received  bool
+		eventType string
+		data      bytes.Buffer
+		hasData   bool
+	)
+
+	reader := bufio.NewReader(res.Body)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+			if errors.Is(err, io.EOF) {
+				return received, nil
+			}
+			return received, bodyErr(err)
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line != "" {
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+
+			switch field {
+			case "":
+				// Comment line, used by servers as keep-alive.
+			case "event":
+				eventType = value
+			case "data":
+				if hasData {
+					data.WriteByte('\n')
+				}
+				data.WriteString(value)
+				hasData = true
+			case "id":
+				if !strings.ContainsRune(value, 0) {
+					stream.lastEventID = value
+				}
+			case "retry":
+				if ms, err := strconv.ParseUint(value, 10, 63); err == nil {
+					stream.retry = time.Duration(ms) * time.Millisecond
+				}
+			}
+			continue
+		}
+
+		if !hasData {
+			eventType = ""
+			continue
+		}
+
+		item := decodeEvent[D](s, stream, eventType, data.Bytes())
+		if !item.SendContext(ctx, next) {
+			return received, ctx.Err()
+		}
+		received = true
+
+		eventType = ""
+		data.Reset()
+		hasData = false
+	}
+}
+
+func decodeEvent[D any](s *settings, stream *sseStream, eventType string, data []byte) rxgo.Item {
+	if eventType == "" {
+		eventType = "message"
+	}
+
+	event := Event[D]{
+		ID:    stream.lastEventID,
+		Event: eventType,
+		Data:  new(D),
+		Retry: stream.retry,
+	}
+
+	if text, ok := any(event.Data).(*string); ok {
+		*text = string(data)
+		return rxgo.Of(&event)
+	}
+
+	err := readJSON(bytes.NewReader(data), event.Data, s.strictness == Strict)
+	if err != nil {
+		return rxgo.Error(fmt.Errorf("event %q: %w", event.ID, err))
+	}
+
+	return rxgo.Of(&event)
+}
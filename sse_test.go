@@ -0,0 +1,95 @@
+package roku
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConsumingEventStreamReconnectsWithLastEventID(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var lastEventIDs []string
+
+	ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", MediaTypeEventStream)
+
+		switch r.Header.Get("Last-Event-ID") {
+		case "":
+			w.WriteHeader(http.StatusOK)
+			_, _ = fmt.Fprint(w, ": keep-alive\nretry: 10\n\n")
+			_, _ = fmt.Fprint(w, "id: 1\nevent: created\ndata: {\"id\":\n")
+			_, _ = fmt.Fprint(w, "data: \"a\"}\n\n")
+			_, _ = fmt.Fprint(w, "id: 2\r\ndata:{\"id\": \"b\"}\r\n\r\n")
+		case "2":
+			w.WriteHeader(http.StatusOK)
+			_, _ = fmt.Fprint(w, "id: 3\ndata: {\"id\": \"c\"}\n\n")
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	defer ts.Close()
+
+	client := NewClient(WithHTTPClient(httpClient), WithBaseURL(ts.URL), WithRetries(time.Millisecond, 3))
+
+	ch := SendSSERx[NoReq, userResV](context.Background(), client, Get, "/events", nil).Observe()
+
+	var got []Event[userResV]
+	for item := range ch {
+		event, err := To[Event[userResV]](item)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, *event)
+	}
+
+	retry := 10 * time.Millisecond
+	want := []Event[userResV]{
+		{ID: "1", Event: "created", Data: &userResV{ID: "a"}, Retry: retry},
+		{ID: "2", Event: "message", Data: &userResV{ID: "b"}, Retry: retry},
+		{ID: "3", Event: "message", Data: &userResV{ID: "c"}, Retry: retry},
+	}
+	if !(cmp.Equal(want, got)) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	wantIDs := []string{"", "2", "3"}
+	if !(cmp.Equal(wantIDs, lastEventIDs)) {
+		t.Error(cmp.Diff(wantIDs, lastEventIDs))
+	}
+}
+
+func TestConsumingEventStreamWithUnavailableServerGivesUp(t *testing.T) {
+	t.Parallel()
+	ts := notFoundResSvr()
+	defer ts.Close()
+
+	ch := SSERx[NoReq, string](
+		context.Background(),
+		httpClient,
+		Get,
+		ts.URL,
+		nil,
+		nil,
+		time.Second,
+		time.Millisecond,
+		2,
+	).Observe()
+
+	_, err := To[Event[string]](<-ch)
+
+	var errHTTP ErrInvalidHTTPStatus
+	if !errors.As(err, &errHTTP) {
+		t.Fatalf("wrong error: %v", err)
+	}
+}
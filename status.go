@@ -0,0 +1,36 @@
+package roku
+
+import "net/http"
+
+var (
+	ErrUnauthorized    = statusErr{msg: "unauthorized", from: http.StatusUnauthorized, to: http.StatusUnauthorized}
+	ErrForbidden       = statusErr{msg: "forbidden", from: http.StatusForbidden, to: http.StatusForbidden}
+	ErrNotFound        = statusErr{msg: "not found", from: http.StatusNotFound, to: http.StatusNotFound}
+	ErrConflict        = statusErr{msg: "conflict", from: http.StatusConflict, to: http.StatusConflict}
+	ErrTooManyRequests = statusErr{msg: "too many requests", from: http.StatusTooManyRequests, to: http.StatusTooManyRequests}
+	ErrClientError     = statusErr{msg: "client error", from: 400, to: 499}
+	ErrServerError     = statusErr{msg: "server error", from: 500, to: 599}
+)
+
+type (
+	// statusErr is a sentinel matched by the ErrInvalidHTTPStatus errors whose
+	// status code is between from and to.
+	statusErr struct {
+		msg      string
+		from, to int
+	}
+)
+
+func (s statusErr) Error() string {
+	return s.msg
+}
+
+// Is makes errors.Is(err, ErrNotFound) and the like hold when the response
+// status code matches the sentinel.
+func (e ErrInvalidHTTPStatus) Is(target error) bool {
+	status, ok := target.(statusErr)
+	if !ok || e.Res == nil {
+		return false
+	}
+	return e.Res.StatusCode >= status.from && e.Res.StatusCode <= status.to
+}
@@ -0,0 +1,79 @@
+package roku
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestFetchingWithFailingServerMatchesStatusSentinels(t *testing.T) {
+	t.Parallel()
+
+	sentinels := []error{
+		ErrUnauthorized,
+		ErrForbidden,
+		ErrNotFound,
+		ErrConflict,
+		ErrTooManyRequests,
+		ErrClientError,
+		ErrServerError,
+	}
+
+	cases := map[string]struct {
+		status int
+		want   []error
+	}{
+		"with unauthorized":      {status: http.StatusUnauthorized, want: []error{ErrUnauthorized, ErrClientError}},
+		"with forbidden":         {status: http.StatusForbidden, want: []error{ErrForbidden, ErrClientError}},
+		"with not found":         {status: http.StatusNotFound, want: []error{ErrNotFound, ErrClientError}},
+		"with conflict":          {status: http.StatusConflict, want: []error{ErrConflict, ErrClientError}},
+		"with too many requests": {status: http.StatusTooManyRequests, want: []error{ErrTooManyRequests, ErrClientError}},
+		"with bad request":       {status: http.StatusBadRequest, want: []error{ErrClientError}},
+		"with bad gateway":       {status: http.StatusBadGateway, want: []error{ErrServerError}},
+	}
+
+	for input, tc := range cases {
+		tc := tc
+		t.Run(input, func(t *testing.T) {
+			t.Parallel()
+			ts := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+				errorResponse(w, r, tc.status, http.StatusText(tc.status))
+			})
+			defer ts.Close()
+
+			client := NewClient(WithHTTPClient(httpClient), WithRetries(time.Millisecond, 0))
+
+			ch := SendRx[NoReq, getUserEnvV1Res](context.Background(), client, Get, ts.URL, nil).Observe()
+
+			_, err := To[Envelope[getUserEnvV1Res]](<-ch)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+
+			for _, sentinel := range sentinels {
+				want := false
+				for _, w := range tc.want {
+					want = want || w == sentinel
+				}
+
+				if got := errors.Is(err, sentinel); got != want {
+					t.Errorf("errors.Is(%v): Expected: %t, Got: %t", sentinel, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchingWithTypedErrorMatchesStatusSentinels(t *testing.T) {
+	t.Parallel()
+	ts := notFoundResSvr()
+	defer ts.Close()
+
+	_, err := FetchE[NoReq, getUserEnvV1Res, upstreamErr](context.Background(), httpClient, Get, ts.URL, nil, nil, DeadLine)
+
+	if !errors.Is(err, ErrNotFound) || errors.Is(err, ErrServerError) {
+		t.Errorf("Expected only %v to match, Got: %v", ErrNotFound, err)
+	}
+}
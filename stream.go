@@ -0,0 +1,174 @@
+package roku
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/reactivex/rxgo/v2"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrStreamPath = rokuErr("JSON path does not lead to an array in the body")
+)
+
+// FetchStreamRx decodes the elements of a JSON array response one at a time
+// and emits each of them as a *I. jsonPath holds the object keys leading to the
+// array when it is nested inside an object. Elements are only decoded as fast
+// as the observer consumes them, so memory stays constant whatever the array
+// length. Streams are never retried, nor cut by the Timeout of client.
+func FetchStreamRx[T ReqI, I any](
+	ctx context.Context,
+	client *http.Client,
+	method HTTPMethod,
+	endpoint string,
+	request *T,
+	headers map[string]string,
+	deadline time.Duration,
+	jsonPath ...string,
+) rxgo.Observable {
+	s := newSettings(
+		WithHTTPClient(client),
+		WithHeaders(headers),
+		WithDeadline(deadline),
+		WithStreamPath(jsonPath...),
+	)
+	return fetchStreamRx[T, I](ctx, &s, method, endpoint, request)
+}
+
+func SendStreamRx[T ReqI, I any](
+	ctx context.Context,
+	client *Client,
+	method HTTPMethod,
+	endpoint string,
+	request *T,
+	opts ...Option,
+) rxgo.Observable {
+	s := client.settings.with(opts...)
+	return fetchStreamRx[T, I](ctx, &s, method, endpoint, request)
+}
+
+func fetchStreamRx[T ReqI, I any](
+	ctx context.Context,
+	s *settings,
+	method HTTPMethod,
+	endpoint string,
+	request *T,
+) rxgo.Observable {
+	stream := s.streaming()
+
+	return rxgo.Defer([]rxgo.Producer{
+		func(_ context.Context, next chan<- rxgo.Item) {
+			res, _, err := roundTrip[T](ctx, &stream, method, endpoint, request)
+			if err != nil {
+				rxgo.Error(err).SendContext(ctx, next)
+				return
+			}
+			defer res.Body.Close()
+
+			dec := json.NewDecoder(res.Body)
+			if s.strictness == Strict {
+				dec.DisallowUnknownFields()
+			}
+
+			err = seekArray(dec, s.streamPath)
+			if err != nil {
+				rxgo.Error(err).SendContext(ctx, next)
+				return
+			}
+
+			for dec.More() {
+				var item I
+
+				err = dec.Decode(&item)
+				if err != nil {
+					rxgo.Error(jsonErr(err)).SendContext(ctx, next)
+					return
+				}
+
+				if !rxgo.Of(&item).SendContext(ctx, next) {
+					return
+				}
+			}
+
+			_, err = dec.Token()
+			if err != nil {
+				rxgo.Error(jsonErr(err)).SendContext(ctx, next)
+			}
+		},
+	})
+}
+
+// seekArray advances dec past the opening bracket of the array found by
+// following path through nested objects.
+func seekArray(dec *json.Decoder, path []string) error {
+	for _, key := range path {
+		err := expectDelim(dec, '{', key)
+		if err != nil {
+			return err
+		}
+
+		for {
+			if !dec.More() {
+				return fmt.Errorf("%w: key %q not found", ErrStreamPath, key)
+			}
+
+			tok, err := dec.Token()
+			if err != nil {
+				return jsonErr(err)
+			}
+
+			if tok == key {
+				break
+			}
+
+			err = skipValue(dec)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return expectDelim(dec, '[', "")
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim, key string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return jsonErr(err)
+	}
+
+	if tok != delim {
+		if key == "" {
+			return fmt.Errorf("%w: found %v", ErrStreamPath, tok)
+		}
+		return fmt.Errorf("%w: found %v before key %q", ErrStreamPath, tok, key)
+	}
+
+	return nil
+}
+
+// skipValue consumes the next value token by token, so skipped siblings are
+// never held in memory.
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return jsonErr(err)
+		}
+
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+
+		if depth == 0 {
+			return nil
+		}
+	}
+}
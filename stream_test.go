@@ -0,0 +1,161 @@
+package roku
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const exportSize = 10_000
+
+func TestStreamingArrayResponseEmitsEachElement(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(exportUsersHandler)
+	defer ts.Close()
+
+	client := NewClient(WithHTTPClient(httpClient), WithBaseURL(ts.URL))
+
+	cases := map[string]struct {
+		endpoint string
+		opts     []Option
+	}{
+		"with top-level array": {
+			endpoint: "/users",
+			opts:     nil,
+		},
+		"with nested array": {
+			endpoint: "/users?wrapped=true",
+			opts:     []Option{WithStreamPath("data", "items")},
+		},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			ch := SendStreamRx[NoReq, userResV](
+				context.Background(),
+				client,
+				Get,
+				tc.endpoint,
+				nil,
+				tc.opts...,
+			).Observe()
+
+			count := 0
+			for item := range ch {
+				got, err := To[userResV](item)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				want := fmt.Sprintf("user-%d", count)
+				if got.ID != want {
+					t.Fatalf("Expected id: %s, Got: %s", want, got.ID)
+				}
+				count++
+			}
+
+			if count != exportSize {
+				t.Errorf("Expected %d items, Got: %d", exportSize, count)
+			}
+		})
+	}
+}
+
+func TestStreamingWithWrongPathReturnsError(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(exportUsersHandler)
+	defer ts.Close()
+
+	cases := map[string]struct {
+		endpoint string
+		jsonPath []string
+	}{
+		"with missing key": {
+			endpoint: ts.URL + "?wrapped=true",
+			jsonPath: []string{"data", "users"},
+		},
+		"with object instead of array": {
+			endpoint: ts.URL + "?wrapped=true",
+			jsonPath: []string{"data"},
+		},
+		"with path into array": {
+			endpoint: ts.URL,
+			jsonPath: []string{"data"},
+		},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			ch := FetchStreamRx[NoReq, userResV](
+				context.Background(),
+				httpClient,
+				Get,
+				tc.endpoint,
+				nil,
+				nil,
+				time.Second,
+				tc.jsonPath...,
+			).Observe()
+
+			_, err := To[userResV](<-ch)
+			if !errors.Is(err, ErrStreamPath) {
+				t.Errorf("wrong error: %v", err)
+			}
+		})
+	}
+}
+
+func TestStreamingWithCanceledContextStopsEmitting(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(exportUsersHandler)
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := FetchStreamRx[NoReq, userResV](ctx, httpClient, Get, ts.URL, nil, nil, time.Second).Observe()
+
+	for i := 0; i < 10; i++ {
+		_, err := To[userResV](<-ch)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	cancel()
+
+	count := 0
+	for range ch {
+		count++
+	}
+
+	if count >= exportSize-10 {
+		t.Errorf("Expected the stream to stop early, Got: %d more items", count)
+	}
+}
+
+func exportUsersHandler(w http.ResponseWriter, r *http.Request) {
+	wrapped := r.URL.Query().Get("wrapped") == "true"
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if wrapped {
+		_, _ = fmt.Fprint(w, `{"meta": {"pages": [1, {"a": "]"}]}, "data": {"total": 1, "items": [`)
+	} else {
+		_, _ = fmt.Fprint(w, `[`)
+	}
+
+	for i := 0; i < exportSize; i++ {
+		if i > 0 {
+			_, _ = fmt.Fprint(w, ",")
+		}
+		_, _ = fmt.Fprintf(w, `{"id": "user-%d"}`, i)
+	}
+
+	if wrapped {
+		_, _ = fmt.Fprint(w, `]}}`)
+	} else {
+		_, _ = fmt.Fprint(w, `]`)
+	}
+}
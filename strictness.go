@@ -0,0 +1,171 @@
+package roku
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Strict fails decoding with ErrBodyUnknownKey when the body holds a
+	// field unknown to the response type.
+	Strict Strictness = iota
+	// Lenient ignores unknown fields.
+	Lenient
+	// Report ignores unknown fields but lists their paths on
+	// Envelope.UnknownFields and passes them to the unknown fields hook.
+	Report
+)
+
+type (
+	Strictness int
+
+	UnknownFieldsHook func(res *http.Response, fields []string)
+)
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// decode unmarshals data into v honoring the configured strictness. Codecs
+// that don't implement LenientCodec always decode with their own rules.
+func (s settings) decode(codec Codec, res *http.Response, data []byte, v any) ([]string, error) {
+	lenient, ok := codec.(LenientCodec)
+	if s.strictness == Strict || !ok {
+		return nil, codec.Unmarshal(data, v)
+	}
+
+	fields, err := lenient.UnmarshalLenient(data, v, s.strictness == Report)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fields) > 0 && s.unknownFieldsHook != nil {
+		s.unknownFieldsHook(res, fields)
+	}
+
+	return fields, nil
+}
+
+// unknownJSONFields returns the sorted paths of the fields in data that t
+// has no destination for, e.g. "user.nickname" or "items[2].extra".
+func unknownJSONFields(data []byte, t reflect.Type) ([]string, error) {
+	var raw any
+
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	collectUnknownFields(raw, t, "", &fields)
+	slices.Sort(fields)
+
+	return fields, nil
+}
+
+func collectUnknownFields(raw any, t reflect.Type, path string, fields *[]string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return
+	}
+
+	switch value := raw.(type) {
+	case map[string]any:
+		switch t.Kind() {
+		case reflect.Struct:
+			known := jsonFields(t)
+			for key, v := range value {
+				field, ok := lookupJSONField(known, key)
+				if !ok {
+					*fields = append(*fields, joinPath(path, key))
+					continue
+				}
+				collectUnknownFields(v, field.Type, joinPath(path, key), fields)
+			}
+		case reflect.Map:
+			for key, v := range value {
+				collectUnknownFields(v, t.Elem(), joinPath(path, key), fields)
+			}
+		}
+	case []any:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return
+		}
+		for i, v := range value {
+			collectUnknownFields(v, t.Elem(), path+"["+strconv.Itoa(i)+"]", fields)
+		}
+	}
+}
+
+// jsonFields maps the JSON names of the fields of t, including the ones
+// promoted from embedded structs, to their struct fields.
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for k, v := range jsonFields(embedded) {
+					if _, ok := fields[k]; !ok {
+						fields[k] = v
+					}
+				}
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field
+	}
+
+	return fields
+}
+
+// lookupJSONField matches key the way encoding/json does: exactly first, then
+// case-insensitively.
+func lookupJSONField(fields map[string]reflect.StructField, key string) (reflect.StructField, bool) {
+	if field, ok := fields[key]; ok {
+		return field, true
+	}
+
+	for name, field := range fields {
+		if strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,149 @@
+package roku
+
+import (
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestSendingWithUnknownFieldsHonorsStrictness(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(driftedUserHandler)
+	defer ts.Close()
+
+	client := NewClient(WithHTTPClient(httpClient), WithBaseURL(ts.URL))
+
+	cases := map[string]struct {
+		strictness Strictness
+		wantErr    error
+		wantFields []string
+	}{
+		"with strict decoding": {
+			strictness: Strict,
+			wantErr:    ErrBodyUnknownKey,
+		},
+		"with lenient decoding": {
+			strictness: Lenient,
+			wantFields: nil,
+		},
+		"with reported unknown fields": {
+			strictness: Report,
+			wantFields: []string{"meta", "user.nickname"},
+		},
+	}
+
+	for input, tc := range cases {
+		t.Run(input, func(t *testing.T) {
+			got, err := Send[NoReq, getUserEnvV1Res](
+				context.Background(),
+				client,
+				Get,
+				"/users/1",
+				nil,
+				WithStrictness(tc.strictness),
+			)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("wrong error: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !(cmp.Equal(userEnvRes, got.Body)) {
+				t.Error(cmp.Diff(userEnvRes, got.Body))
+			}
+
+			if !(cmp.Equal(tc.wantFields, got.UnknownFields)) {
+				t.Error(cmp.Diff(tc.wantFields, got.UnknownFields))
+			}
+		})
+	}
+}
+
+func TestSendingWithReportStrictnessCallsHook(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(driftedUserHandler)
+	defer ts.Close()
+
+	var mu sync.Mutex
+	var reported []string
+
+	client := NewClient(
+		WithHTTPClient(httpClient),
+		WithStrictness(Report),
+		WithUnknownFieldsHook(func(res *http.Response, fields []string) {
+			mu.Lock()
+			defer mu.Unlock()
+			reported = append(reported, fields...)
+		}),
+	)
+
+	_, err := Send[NoReq, getUserEnvV1Res](context.Background(), client, Get, ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"meta", "user.nickname"}
+	if !(cmp.Equal(want, reported)) {
+		t.Error(cmp.Diff(want, reported))
+	}
+}
+
+func TestCollectingUnknownFieldsWithNestedValuesReturnsPaths(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		ID string `json:"id"`
+	}
+
+	type page struct {
+		Items  []item           `json:"items"`
+		Labels map[string]item  `json:"labels"`
+		Extra  map[string]any   `json:"extra"`
+		Skip   string           `json:"-"`
+		Nested *getUserEnvV1Res `json:"nested"`
+		Raw    *nonSerdeType    `json:"raw"`
+	}
+
+	data := []byte(`{
+		"items": [{"id": "1"}, {"id": "2", "price": 3}],
+		"labels": {"a": {"id": "3", "color": "red"}},
+		"extra": {"anything": {"goes": true}},
+		"Skip": "x",
+		"NESTED": {"user": {"Name": "Marco", "age": 40}},
+		"raw": {"whatever": 1}
+	}`)
+
+	got, err := unknownJSONFields(data, reflect.TypeOf(&page{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"NESTED.user.age", "Skip", "items[1].price", "labels.a.color"}
+	if !(cmp.Equal(want, got)) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func driftedUserHandler(w http.ResponseWriter, r *http.Request) {
+	env := envelope{
+		"user": map[string]any{
+			"name":       userRes.Name,
+			"sms_number": userRes.SmsNumber,
+			"nickname":   "marc",
+		},
+		"meta": map[string]any{"version": 2},
+	}
+
+	err := write(w, http.StatusOK, env, nil)
+	if err != nil {
+		serverErrorResponse(w, r)
+	}
+}